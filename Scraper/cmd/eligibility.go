@@ -0,0 +1,38 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"ntumods/pkg/eligibility"
+	"ntumods/pkg/utils"
+	"os"
+	"strings"
+)
+
+// runEligibility lists the modules of a semester a student may take as UE, PE or BDE, and why the others are excluded
+func runEligibility(storage utils.Storage, args []string) error {
+	flags := flag.NewFlagSet("eligibility", flag.ExitOnError)
+	semester := flags.String("semester", "", "published semester to read, e.g. 2023_1")
+	programme := flags.String("programme", "", "programme code of the student, e.g. CSC")
+	year := flags.Int("year", 0, "year of study of the student, restrictions by year of study apply if omitted")
+	admissionYear := flags.Int("admission-year", 0, "year the student was admitted, restrictions by admission year apply if omitted")
+	kind := flags.String("kind", "", "ue, pe or bde, any of them if omitted")
+	flags.Parse(args)
+
+	*kind = strings.ToLower(*kind)
+	if *semester == "" || *programme == "" || !eligibility.ValidKind(*kind) {
+		return fmt.Errorf("usage: eligibility -semester 2023_1 -programme CSC [-year 2] [-admission-year 2022] [-kind ue|pe|bde]")
+	}
+
+	modules, err := utils.LoadSemester(storage, *semester)
+	if err != nil {
+		return err
+	}
+
+	student := eligibility.Student{Programme: *programme, Year: *year, AdmissionYear: *admissionYear}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(eligibility.Filter(modules, student, *kind))
+}
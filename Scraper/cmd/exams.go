@@ -0,0 +1,38 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"ntumods/pkg/dto"
+	"ntumods/pkg/planner"
+	"ntumods/pkg/utils"
+	"os"
+)
+
+// runExams reports overlapping and back-to-back exams among the module codes given as arguments
+func runExams(storage utils.Storage, args []string) error {
+	flags := flag.NewFlagSet("exams", flag.ExitOnError)
+	semester := flags.String("semester", "", "published semester to read, e.g. 2023_1")
+	flags.Parse(args)
+
+	if *semester == "" || flags.NArg() == 0 {
+		return fmt.Errorf("usage: exams -semester 2023_1 CODE [CODE...]")
+	}
+
+	modules, err := loadModules(storage, *semester, flags.Args())
+	if err != nil {
+		return err
+	}
+
+	var exams []dto.ExamSchedule
+	for _, module := range modules {
+		exam := module.Exam
+		exam.Code = module.Code
+		exams = append(exams, exam)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(planner.FindExamClashes(exams))
+}
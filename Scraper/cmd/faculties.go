@@ -0,0 +1,44 @@
+package main
+
+import (
+	"net/http"
+	"ntumods/pkg/faculty"
+)
+
+// handleFaculties serves GET /faculties, describing the faculty mapping in use
+func handleFaculties(loader *faculty.Loader) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, loader.Status())
+	}
+}
+
+// handleReloadFaculties serves POST /faculties/reload, reading the faculty mapping from its source again. An invalid
+// mapping is rejected with 422 Unprocessable Entity listing its problems, and the mapping in use is kept.
+// Semesters already being scraped keep the mapping they started with.
+func handleReloadFaculties(loader *faculty.Loader) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		status, err := loader.Reload()
+		if _, invalid := err.(*faculty.ValidationError); invalid {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, status)
+	}
+}
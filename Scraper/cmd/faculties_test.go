@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"ntumods/pkg/dto"
+	"ntumods/pkg/faculty"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHandleReloadFaculties(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "faculty.json")
+	writeMapping := func(mapping string) {
+		if err := os.WriteFile(path, []byte(mapping), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loader := faculty.NewLoader(path)
+	reload := handleReloadFaculties(loader)
+	status := handleFaculties(loader)
+
+	post := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		reload(w, httptest.NewRequest(http.MethodPost, "/faculties/reload", nil))
+		return w
+	}
+	current := func() dto.FacultyMappingStatus {
+		w := httptest.NewRecorder()
+		status(w, httptest.NewRequest(http.MethodGet, "/faculties", nil))
+
+		var s dto.FacultyMappingStatus
+		if err := json.Unmarshal(w.Body.Bytes(), &s); err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	// The file does not exist yet, the embedded mapping stays in use
+	if w := post(); w.Code != http.StatusBadGateway {
+		t.Errorf("reloading a missing file returned %d, expected 502", w.Code)
+	}
+	if source := current().Source; source != "embedded" {
+		t.Errorf("source is %q, expected embedded", source)
+	}
+
+	writeMapping(`{"AB;AC": {"Faculty": "Nanyang Business School", "Code": "NBS"}}`)
+	if w := post(); w.Code != http.StatusOK {
+		t.Fatalf("reloading a valid mapping returned %d: %s", w.Code, w.Body)
+	}
+	loaded := current()
+	if loaded.Source != path || loaded.Prefixes != 2 {
+		t.Errorf("status is %+v after reloading", loaded)
+	}
+
+	writeMapping(`{"AB": {"Faculty": "Nanyang Business School", "Code": "NBS"}, "AB": {"Faculty": "Nanyang Business School", "Code": "NBS"}}`)
+	if w := post(); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reloading an invalid mapping returned %d, expected 422", w.Code)
+	}
+	if s := current(); s != loaded {
+		t.Errorf("status is %+v after an invalid mapping, expected %+v", s, loaded)
+	}
+
+	w := httptest.NewRecorder()
+	reload(w, httptest.NewRequest(http.MethodGet, "/faculties/reload", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET returned %d, expected 405", w.Code)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"ntumods/pkg/dto"
+	"ntumods/pkg/graph"
+	"ntumods/pkg/utils"
+	"os"
+)
+
+// runGraph prints the prerequisite graph of a published semester, or what a single module unlocks
+func runGraph(storage utils.Storage, args []string) error {
+	flags := flag.NewFlagSet("graph", flag.ExitOnError)
+	semester := flags.String("semester", "", "published semester to read, e.g. 2023_1")
+	format := flags.String("format", "json", "output format: dot, graphml or json")
+	unlocks := flags.String("unlocks", "", "print the modules unlocked by this module code instead of the graph")
+	transitive := flags.Bool("transitive", false, "with -unlocks, include modules that are unlocked indirectly")
+	flags.Parse(args)
+
+	if *semester == "" {
+		return fmt.Errorf("-semester is required")
+	}
+
+	modules, err := utils.LoadSemester(storage, *semester)
+	if err != nil {
+		return err
+	}
+
+	courses := make([]dto.Course, 0, len(modules))
+	for _, m := range modules {
+		courses = append(courses, m.Course)
+	}
+	g := graph.Build(courses)
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	if *unlocks != "" {
+		if *transitive {
+			return encoder.Encode(g.AllUnlocks(*unlocks))
+		}
+		return encoder.Encode(g.Unlocks(*unlocks))
+	}
+
+	switch *format {
+	case "dot":
+		return g.WriteDOT(os.Stdout)
+	case "graphml":
+		return g.WriteGraphML(os.Stdout)
+	case "json":
+		return encoder.Encode(g.Adjacency())
+	default:
+		return fmt.Errorf("unknown format: %s", *format)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"ntumods/pkg/dto"
+	"ntumods/pkg/ical"
+	"ntumods/pkg/utils"
+	"os"
+	"strings"
+)
+
+// runICS writes the classes and exams of the CODE:INDEX selections given as arguments as an iCalendar file
+func runICS(storage utils.Storage, args []string) error {
+	flags := flag.NewFlagSet("ics", flag.ExitOnError)
+	semester := flags.String("semester", "", "published semester to read, e.g. 2023_1")
+	start := flags.String("start", "", "Monday of teaching week 1, e.g. 2023-08-14, overrides the published calendar")
+	recessAfter := flags.Int("recess-after", 7, "teaching week followed by the recess week, used with -start")
+	flags.Parse(args)
+
+	if *semester == "" || flags.NArg() == 0 {
+		return fmt.Errorf("usage: ics -semester 2023_1 [-start 2023-08-14] CODE:INDEX [CODE:INDEX...]")
+	}
+
+	var codes, indexes []string
+	for _, arg := range flags.Args() {
+		parts := strings.SplitN(arg, ":", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("selection %q is not in the form CODE:INDEX", arg)
+		}
+		codes = append(codes, parts[0])
+		indexes = append(indexes, parts[1])
+	}
+
+	modules, err := loadModules(storage, *semester, codes)
+	if err != nil {
+		return err
+	}
+
+	var selections []ical.Selection
+	for i, module := range modules {
+		selections = append(selections, ical.Selection{Module: module, Index: indexes[i]})
+	}
+
+	calendar := dto.AcademicCalendar{
+		AcadYearSem:     *semester,
+		StartDate:       *start,
+		RecessAfterWeek: *recessAfter,
+	}
+
+	if *start == "" {
+		published, err := utils.LoadCalendar(storage, *semester)
+		if err != nil {
+			return fmt.Errorf("no calendar was published for %s, pass -start instead: %v", *semester, err)
+		}
+		if published.StartDate == "" {
+			return fmt.Errorf("the calendar published for %s has no start date, pass -start instead", *semester)
+		}
+		calendar = *published
+	}
+
+	return ical.Export(os.Stdout, selections, calendar)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"ntumods/pkg/dto"
+	"ntumods/pkg/jobs"
+	"strings"
+)
+
+// handleSubmitJob serves POST /jobs, queueing a scrape described by a dto.JobRequest and returning its dto.Job. The
+// semesters are resolved by the job, follow its Location to see them or why they could not be scraped.
+func handleSubmitJob(manager *jobs.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var request dto.JobRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, fmt.Sprintf("invalid job request: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		job, err := manager.Submit(request.Semester, request.Scope, request.DryRun)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		fmt.Printf("Job %s queued for semester %q\n", job.ID, job.Selection)
+
+		w.Header().Set("Location", "/jobs/"+job.ID)
+		writeJSON(w, http.StatusAccepted, job)
+	}
+}
+
+// handleGetJob serves GET /jobs/{id}, returning the status and progress of the job
+func handleGetJob(manager *jobs.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id := strings.TrimPrefix(r.URL.Path, "/jobs/")
+		job, exists := manager.Get(id)
+		if !exists {
+			http.NotFound(w, r)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, job)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		fmt.Println("Error writing response:", err)
+	}
+}
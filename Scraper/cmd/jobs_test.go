@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"ntumods/pkg/dto"
+	"ntumods/pkg/jobs"
+	"strings"
+	"testing"
+)
+
+const testAdminToken = "s3cret"
+
+func adminRequest(method string, target string, body string) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	r.Header.Set("Authorization", "Bearer "+testAdminToken)
+	return r
+}
+
+func TestRequireAdmin(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}
+
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		status        int
+	}{
+		{"token", testAdminToken, "Bearer " + testAdminToken, http.StatusNoContent},
+		{"no header", testAdminToken, "", http.StatusUnauthorized},
+		{"wrong token", testAdminToken, "Bearer other", http.StatusUnauthorized},
+		{"prefix of the token", testAdminToken, "Bearer s3c", http.StatusUnauthorized},
+		{"not a bearer token", testAdminToken, testAdminToken, http.StatusUnauthorized},
+		// Without a token configured nobody gets in, not even with an empty one
+		{"disabled", "", "Bearer ", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/jobs", nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+
+			w := httptest.NewRecorder()
+			requireAdmin(tt.token, ok)(w, r)
+			if w.Code != tt.status {
+				t.Errorf("status is %d, expected %d", w.Code, tt.status)
+			}
+		})
+	}
+}
+
+func TestHandleSubmitJob(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	// Resolving the semesters waits for WIS, which never answers during the test
+	manager := jobs.NewManager(func(selection string) ([]string, error) {
+		<-release
+		return []string{"2023_1"}, nil
+	}, func(job dto.Job, progress *jobs.Progress) error {
+		return nil
+	})
+	submit := requireAdmin(testAdminToken, handleSubmitJob(manager))
+
+	w := httptest.NewRecorder()
+	submit(w, adminRequest(http.MethodPost, "/jobs", `{"semester": "latest", "scope": ["ACC"]}`))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("POST /jobs returned %d: %s", w.Code, w.Body)
+	}
+
+	var job dto.Job
+	if err := json.Unmarshal(w.Body.Bytes(), &job); err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != dto.JOB_QUEUED || job.Selection != "latest" || len(job.Semesters) != 0 {
+		t.Errorf("job is %+v, expected it to be queued for latest", job)
+	}
+	if location := w.Header().Get("Location"); location != "/jobs/"+job.ID {
+		t.Errorf("Location is %q", location)
+	}
+
+	w = httptest.NewRecorder()
+	submit(w, adminRequest(http.MethodPost, "/jobs", `{"semester": `))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("an invalid request returned %d, expected 400", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	submit(w, httptest.NewRequest(http.MethodPost, "/jobs", strings.NewReader(`{"semester": "latest"}`)))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("a request without the admin token returned %d, expected 401", w.Code)
+	}
+}
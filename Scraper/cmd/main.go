@@ -1,26 +1,37 @@
 package main
 
 import (
-	"encoding/json"
+	"bytes"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"ntumods/pkg/cassette"
+	"ntumods/pkg/diff"
 	"ntumods/pkg/dto"
+	"ntumods/pkg/faculty"
+	"ntumods/pkg/feed"
+	"ntumods/pkg/graph"
+	"ntumods/pkg/jobs"
+	"ntumods/pkg/programme"
 	"ntumods/pkg/scraper"
+	"ntumods/pkg/search"
 	"ntumods/pkg/utils"
+	"ntumods/pkg/webhook"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 const maxWorkers = 3
 
-var processedCourses sync.Map
-var moduleList []dto.ModuleLite
-
 type courseDetailParams struct {
 	AcadYearSem    string
 	CourseYearProg string
@@ -31,29 +42,135 @@ type examDetailParams struct {
 	Code        string
 }
 
-type Combined struct {
-	dto.Course
-	Exam     dto.ExamSchedule `json:"exam"`
-	Schedule []dto.Schedule   `json:"schedule"`
+// scrapeOptions narrows down what executeScraper scrapes and publishes
+type scrapeOptions struct {
+	// Scope limits the scrape to the programmes starting with any of these prefixes, all programmes when empty
+	Scope []string
+	// DryRun scrapes without publishing anything
+	DryRun bool
+	// Progress, when set, is updated as the scrape goes along
+	Progress *jobs.Progress
+	// Webhooks, when set, are notified in the background of the changes of every semester scraped
+	Webhooks *webhook.Dispatcher
+	// Faculties is the faculty mapping the modules are resolved with
+	Faculties *faculty.Loader
+}
+
+// semesterScrape holds the state of scraping a single semester
+type semesterScrape struct {
+	progress         *jobs.Progress
+	processedCourses sync.Map
+	courseDetailWg   sync.WaitGroup
+	examDetailWg     sync.WaitGroup
+	// failures counts the requests to WIS that failed, a semester missing any of them is not published
+	failures int32
+
+	// programmeNames maps each CourseYearProg to its name, offerings lists the programmes each module code was
+	// found under
+	programmeNames map[string]string
+	offeringsMu    sync.Mutex
+	offerings      map[string][]dto.ProgrammeOffering
+}
+
+// addOffering records that courseYearProg offers the module with code
+func (run *semesterScrape) addOffering(code string, courseYearProg string, status string) {
+	offering := programme.NewOffering(courseYearProg, run.programmeNames[courseYearProg], status)
+
+	run.offeringsMu.Lock()
+	defer run.offeringsMu.Unlock()
+	run.offerings[code] = programme.Merge(run.offerings[code], []dto.ProgrammeOffering{offering})
 }
 
-var courseDetailWg sync.WaitGroup
-var examDetailWg sync.WaitGroup
+// fail reports a request to WIS that failed for good
+func (run *semesterScrape) fail(worker string, err error) {
+	atomic.AddInt32(&run.failures, 1)
+	fmt.Println("Error in "+worker+":", err)
+}
 
-func executeScraper() {
-	err := godotenv.Load("../.env")
+// resolveSemesters lists the semesters offered by WIS to pick the ones in selection, see scraper.ResolveSemesters
+func resolveSemesters(wis *scraper.Scraper, selection string) ([]string, error) {
+	init, err := wis.GetCourseSchedulePair("")
 	if err != nil {
-		log.Fatal("Error loading .env file")
+		return nil, fmt.Errorf("[resolveSemesters] Failed to list semesters: %v", err)
 	}
 
-	facultyInformation, err := populateFacultyInformation()
+	semesters, err := scraper.ResolveSemesters(selection, init.AcadYearSem)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("[resolveSemesters] %v", err)
 	}
 
-	init, err := scraper.GetCourseSchedulePair()
+	return semesters, nil
+}
+
+// executeScraper scrapes and publishes each of semesters in turn
+func executeScraper(wis *scraper.Scraper, storage utils.Storage, semesters []string, options scrapeOptions) error {
+	calendars, err := utils.LoadAcademicCalendars(os.Getenv("ACADEMIC_CALENDAR_PATH"))
 	if err != nil {
-		panic(err)
+		return err
+	}
+
+	// A dry run publishes into memory that is thrown away afterwards
+	published := storage
+	if options.DryRun {
+		storage = utils.NewMemoryStorage()
+	}
+
+	for _, semester := range semesters {
+		options.Progress.Update(func(progress *dto.JobProgress) {
+			progress.Semester = semester
+		})
+
+		// A semester without a calendar is still scraped, only its classes cannot be given dates
+		var calendar *dto.AcademicCalendar
+		if c, exists := calendars[semester]; exists && c.StartDate != "" {
+			calendar = &c
+		} else {
+			fmt.Println("Warning: no academic calendar configured for", semester+",", dto.CALENDAR_FILE, "will not be published, add the start of teaching week 1 to the file at ACADEMIC_CALENDAR_PATH or to data/calendar.json")
+		}
+
+		// Each semester reports the codes it found no faculty for on its own
+		faculties := options.Faculties.Resolver()
+		if err = scrapeSemester(wis, storage, published, semester, faculties, calendar, options); err != nil {
+			return err
+		}
+
+		options.Progress.Update(func(progress *dto.JobProgress) {
+			progress.SemestersDone++
+		})
+	}
+
+	return nil
+}
+
+// scrapeSemester scrapes every programme offered in semester and publishes it under <semester>/, comparing it with
+// the snapshot last published to published, which only differs from storage in a dry run. calendar is nil when
+// none is configured for semester.
+func scrapeSemester(wis *scraper.Scraper, storage utils.Storage, published utils.Storage, semester string, faculties *faculty.Resolver, calendar *dto.AcademicCalendar, options scrapeOptions) error {
+	// The programmes offered differ between semesters, so they are listed again for each
+	init, err := wis.GetCourseSchedulePair(semester)
+	if err != nil {
+		return fmt.Errorf("[scrapeSemester] Failed to list programmes of %s: %v", semester, err)
+	}
+
+	var programmes []string
+	for _, courseYearProg := range init.CourseYearProg {
+		if inScope(courseYearProg, options.Scope) {
+			programmes = append(programmes, courseYearProg)
+		}
+	}
+
+	if len(programmes) == 0 {
+		return fmt.Errorf("[scrapeSemester] No programmes of %s are in scope %v", semester, options.Scope)
+	}
+
+	options.Progress.Update(func(progress *dto.JobProgress) {
+		progress.ProgrammesTotal += len(programmes)
+	})
+
+	run := &semesterScrape{
+		progress:       options.Progress,
+		programmeNames: init.ProgrammeNames,
+		offerings:      make(map[string][]dto.ProgrammeOffering),
 	}
 
 	courseYearProgChan := make(chan courseDetailParams, maxWorkers)
@@ -62,40 +179,30 @@ func executeScraper() {
 
 	// Start worker A goroutines
 	for i := 0; i < maxWorkers; i++ {
-		courseDetailWg.Add(1)
-		go getContentOfCourses(courseYearProgChan, facultyInformation)
+		run.courseDetailWg.Add(1)
+		go run.getContentOfCourses(wis, courseYearProgChan, faculties)
 	}
 
 	// Start worker B goroutines
 	for i := 0; i < maxWorkers; i++ {
-		courseDetailWg.Add(1)
-		go getCourseTimetable(courseChan, examChan)
+		run.courseDetailWg.Add(1)
+		go run.getCourseTimetable(wis, courseChan, examChan)
 	}
 
 	// Start worker C goroutines
 	for i := 0; i < maxWorkers*2; i++ {
-		examDetailWg.Add(1)
-		go getExamSchedule(examChan)
-	}
-
-	numSemesters := len(init.AcadYearSem)
-	latestSemester := init.AcadYearSem[numSemesters-1]
-	for i := numSemesters - 1; i >= 0; i-- {
-		semester := init.AcadYearSem[i]
-		if !strings.Contains(semester, "Special Term") {
-			latestSemester = semester
-			break
-		}
+		run.examDetailWg.Add(1)
+		go run.getExamSchedule(wis, examChan)
 	}
 
 	// Send CourseYearProg data to worker A and worker B goroutines
-	numCourses := len(init.CourseYearProg)
+	numCourses := len(programmes)
 	for i := 0; i < numCourses; i++ {
-		courseYearProg := init.CourseYearProg[i]
+		courseYearProg := programmes[i]
 
 		request := courseDetailParams{
 			CourseYearProg: courseYearProg,
-			AcadYearSem:    latestSemester,
+			AcadYearSem:    semester,
 		}
 
 		courseYearProgChan <- request
@@ -104,19 +211,50 @@ func executeScraper() {
 
 	close(courseYearProgChan)
 	close(courseChan)
-	courseDetailWg.Wait()
+	run.courseDetailWg.Wait()
 
 	close(examChan)
-	examDetailWg.Wait()
+	run.examDetailWg.Wait()
+
+	// Modules of a programme that failed would look removed and their classes or exam would look dropped, so
+	// nothing is published and the snapshot is left for the next run to compare against
+	if failures := atomic.LoadInt32(&run.failures); failures > 0 {
+		return fmt.Errorf("[scrapeSemester] %d request(s) to WIS failed, %s was not published", failures, semester)
+	}
+
+	// The snapshot published by the previous run, to tell what this run changed, there is none the first time
+	previous, err := utils.LoadSemester(published, semester)
+	if err != nil && !errors.Is(err, utils.ErrNotFound) {
+		return fmt.Errorf("[scrapeSemester] Failed to read the published snapshot of %s: %v", semester, err)
+	}
 
 	numModules := 0
-	processedCourses.Range(func(key, value interface{}) bool {
+	var moduleList []dto.ModuleLite
+	var courses []dto.Course
+	var exams []dto.ExamSchedule
+	var current []dto.Combined
+	scraped := make(map[string]bool)
+
+	// A scoped scrape only finds the programmes in scope, keep the other programmes found before
+	previousOfferings := make(map[string][]dto.ProgrammeOffering)
+	if len(options.Scope) > 0 {
+		for _, m := range previous {
+			for _, o := range m.Programmes {
+				if !inScope(o.Programme, options.Scope) {
+					previousOfferings[m.Code] = append(previousOfferings[m.Code], o)
+				}
+			}
+		}
+	}
+	var uploadErr error
+	run.processedCourses.Range(func(key, value interface{}) bool {
 		// For some reason there's always an empty Course.json generated, this is to bypass that
 		if key == "Course" {
 			return true
 		}
 
-		c := value.(Combined)
+		c := value.(dto.Combined)
+		c.Programmes = programme.Merge(run.offerings[c.Code], previousOfferings[c.Code])
 
 		moduleLite := dto.ModuleLite{
 			Code:        c.Course.Code,
@@ -128,29 +266,217 @@ func executeScraper() {
 
 		if !utils.IsEmpty(moduleLite) {
 			moduleList = append(moduleList, moduleLite)
+			courses = append(courses, c.Course)
+			exams = append(exams, c.Exam)
+			current = append(current, c)
+			scraped[c.Course.Code] = true
 		}
 
 		numModules += 1
 
 		fileName := key.(string)
-		blobName := filepath.Join(latestSemester, fileName+".json")
-		if err = utils.UploadFileToBlobStorage(blobName, value); err != nil {
-			fmt.Println("Error uploading file to blob storage:", err)
+		blobName := path.Join(semester, fileName+".json")
+		if uploadErr = utils.PutJSON(storage, blobName, c); uploadErr != nil {
 			return false
 		}
 		return true
 	})
+	if uploadErr != nil {
+		return fmt.Errorf("[scrapeSemester] Failed to upload the modules of %s: %v", semester, uploadErr)
+	}
+
+	options.Progress.Update(func(progress *dto.JobProgress) {
+		progress.ModulesScraped += numModules
+	})
+
+	// A scoped scrape only covers some programmes, keep the other modules published before in the module list,
+	// prerequisite graph and calendar
+	if len(options.Scope) > 0 {
+		for _, m := range previous {
+			if scraped[m.Code] {
+				continue
+			}
+
+			moduleList = append(moduleList, dto.ModuleLite{
+				Code:        m.Code,
+				Module:      m.Title,
+				AU:          m.AU,
+				Description: m.Description,
+				Faculty:     m.Faculty,
+			})
+			courses = append(courses, m.Course)
+			exams = append(exams, m.Exam)
+			current = append(current, m)
+		}
+	}
+
+	sort.Slice(moduleList, func(i, j int) bool { return moduleList[i].Code < moduleList[j].Code })
+
+	blobName := path.Join(semester, dto.MODULE_LIST_FILE)
+	if err = utils.PutJSON(storage, blobName, moduleList); err != nil {
+		fmt.Println("Error uploading file to storage:", err)
+	}
+
+	prerequisiteGraph := graph.Build(courses)
+	for _, cycle := range prerequisiteGraph.Cycles() {
+		fmt.Println("Prerequisite cycle found:", strings.Join(cycle, ", "))
+	}
+
+	blobName = path.Join(semester, dto.PREREQUISITE_GRAPH_FILE)
+	if err = utils.PutJSON(storage, blobName, prerequisiteGraph.Adjacency()); err != nil {
+		fmt.Println("Error uploading file to storage:", err)
+	}
+
+	exclusions := graph.BuildExclusions(courses).Adjacency()
+	for _, e := range exclusions.OneSided {
+		fmt.Println("One-sided mutual exclusion found:", e.Module, "excludes", e.Excludes, "but not the other way round")
+	}
+
+	blobName = path.Join(semester, dto.EXCLUSION_GRAPH_FILE)
+	if err = utils.PutJSON(storage, blobName, exclusions); err != nil {
+		fmt.Println("Error uploading file to storage:", err)
+	}
+
+	blobName = path.Join(semester, dto.SEARCH_INDEX_FILE)
+	if err = utils.PutJSON(storage, blobName, search.Build(courses)); err != nil {
+		fmt.Println("Error uploading file to storage:", err)
+	}
+
+	publishProgrammes(storage, semester, current)
+
+	// Published even when empty, so that a report of an earlier run does not linger once the mapping is fixed
+	unmatched := faculties.Unmatched()
+	if len(unmatched) > 0 {
+		fmt.Println("Modules of", semester, "matching no faculty:", strings.Join(unmatched, ", "))
+	}
+
+	blobName = path.Join(semester, dto.UNMATCHED_FACULTIES_FILE)
+	if err = utils.PutJSON(storage, blobName, unmatched); err != nil {
+		fmt.Println("Error uploading file to storage:", err)
+	}
+
+	if calendar != nil {
+		// Configured exam periods take precedence over the one spanned by the scraped exams
+		semesterCalendar := *calendar
+		semesterCalendar.AcadYearSem = semester
+		if semesterCalendar.Exams == nil {
+			semesterCalendar.Exams = dto.ExamPeriod(exams)
+		}
+
+		blobName = path.Join(semester, dto.CALENDAR_FILE)
+		if err = utils.PutJSON(storage, blobName, semesterCalendar); err != nil {
+			fmt.Println("Error uploading file to storage:", err)
+		}
+	}
+
+	now := time.Now()
+	changelog := diff.Compare(previous, current)
+	changelog.Semester = semester
+	changelog.GeneratedAt = now.Format(time.RFC3339)
+
+	fmt.Println("Changes in", semester, ": added =", len(changelog.Added), ", removed =", len(changelog.Removed), ", changed =", len(changelog.Changed))
+
+	blobName = path.Join(semester, dto.CHANGES_FILE)
+	if err = utils.PutJSON(storage, blobName, changelog); err != nil {
+		fmt.Println("Error uploading file to storage:", err)
+	}
+
+	// Read the history before adding this run to it, a dry run only writes this run to its own storage
+	history, historyErr := utils.LoadChangelogs(published, semester, dto.FEED_HISTORY-1)
+
+	blobName = path.Join(semester, dto.CHANGES_DIR, now.UTC().Format("20060102T150405.000Z")+".json")
+	if err = utils.PutJSON(storage, blobName, changelog); err != nil {
+		fmt.Println("Error uploading file to storage:", err)
+	}
+
+	// Feeds rebuilt from part of the history would drop the entries of earlier runs, and would lose this run once the
+	// history can be read again, so they are left as they were
+	switch {
+	case historyErr != nil:
+		fmt.Println("Not updating the feeds of", semester, ":", historyErr)
+	case err != nil:
+		fmt.Println("Not updating the feeds of", semester, ": its changelog was not stored")
+	default:
+		publishFeeds(storage, semester, moduleList, append(history, changelog), now)
+	}
+
+	// Only the runs the feeds cover are kept
+	if err = utils.PruneChangelogs(storage, semester, dto.FEED_HISTORY); err != nil {
+		fmt.Println("Error pruning changelogs:", err)
+	}
 
-	blobName := filepath.Join(latestSemester, "moduleList.json")
-	if err = utils.UploadFileToBlobStorage(blobName, moduleList); err != nil {
-		fmt.Println("Error uploading file to blob storage:", err)
+	// The first scrape of a semester adds every module, which is not worth notifying anyone of. A scrape missing
+	// any request returned above, so the changelog is complete and is delivered without holding up the next semester.
+	if options.Webhooks != nil && !options.DryRun && !changelog.Initial {
+		options.Webhooks.Enqueue(changelog)
 	}
 
-	fmt.Println("Extraction Complete (numModules = ", numModules, ")")
+	fmt.Println("Extraction Complete (semester =", semester, ", numModules = ", numModules, ")")
+	return nil
 }
 
-func getContentOfCourses(courseYearProgChan <-chan courseDetailParams, facultyInformation map[string]dto.Faculty) {
-	defer courseDetailWg.Done() // Decrement the counter when the goroutine completes
+// publishProgrammes publishes the programmes offering modules in semester, each with the modules it offers.
+// Programmes published by earlier runs that no longer offer any module are removed.
+func publishProgrammes(storage utils.Storage, semester string, modules []dto.Combined) {
+	summaries, indexes := programme.Index(modules)
+
+	blobName := path.Join(semester, dto.PROGRAMMES_FILE)
+	if err := utils.PutJSON(storage, blobName, summaries); err != nil {
+		fmt.Println("Error uploading file to storage:", err)
+	}
+
+	written := make(map[string]bool)
+	for _, index := range indexes {
+		blobName = path.Join(semester, dto.PROGRAMMES_DIR, programme.Key(index.Programme)+".json")
+		written[blobName] = true
+		if err := utils.PutJSON(storage, blobName, index); err != nil {
+			fmt.Println("Error uploading file to storage:", err)
+		}
+	}
+
+	keys, err := storage.List(path.Join(semester, dto.PROGRAMMES_DIR) + "/")
+	if err != nil {
+		fmt.Println("Error listing storage:", err)
+		return
+	}
+	for _, key := range keys {
+		if !written[key] {
+			if err = storage.Delete(key); err != nil {
+				fmt.Println("Error deleting file from storage:", err)
+			}
+		}
+	}
+}
+
+// publishFeeds publishes the Atom feed of the changes in history for semester and one for each faculty in moduleList,
+// generated by the run at now
+func publishFeeds(storage utils.Storage, semester string, moduleList []dto.ModuleLite, history []dto.Changelog, now time.Time) {
+	faculties := map[string]bool{"": true}
+	for _, m := range moduleList {
+		if m.Faculty.Code != "" {
+			faculties[m.Faculty.Code] = true
+		}
+	}
+
+	for code := range faculties {
+		blobName := path.Join(semester, dto.FEED_FILE)
+		if code != "" {
+			blobName = path.Join(semester, dto.FEEDS_DIR, code+".atom")
+		}
+
+		var buf bytes.Buffer
+		if err := feed.Write(&buf, semester, code, history, now); err != nil {
+			fmt.Println("Error generating feed:", err)
+			continue
+		}
+		if err := storage.Put(blobName, buf.Bytes()); err != nil {
+			fmt.Println("Error uploading file to storage:", err)
+		}
+	}
+}
+
+func (run *semesterScrape) getContentOfCourses(wis *scraper.Scraper, courseYearProgChan <-chan courseDetailParams, faculties *faculty.Resolver) {
+	defer run.courseDetailWg.Done() // Decrement the counter when the goroutine completes
 	for courseYearProg := range courseYearProgChan {
 		fmt.Println("[WorkerA] Processing Course Content (", courseYearProg.AcadYearSem, ", ", courseYearProg.CourseYearProg, ")")
 
@@ -165,9 +491,9 @@ func getContentOfCourses(courseYearProgChan <-chan courseDetailParams, facultyIn
 			Semester:    semester,
 		}
 
-		res, err := scraper.GetContentOfCourses(request)
+		res, err := wis.GetContentOfCourses(request)
 		if err != nil {
-			fmt.Println("Error in getContentOfCourses:", err)
+			run.fail("getContentOfCourses", err)
 			continue
 		}
 
@@ -176,31 +502,20 @@ func getContentOfCourses(courseYearProgChan <-chan courseDetailParams, facultyIn
 				continue
 			}
 
-			// Need to resolve the course code here, course code could start with either 2 or 3 characters
-			// We will first attempt to map 2 characters, because it could be possible AA might supersede AAA
-			var faculty dto.Faculty
-			codeA := c.Code[:2]
-			if f, exists := facultyInformation[codeA]; exists {
-				faculty = f
-			}
-
-			codeB := c.Code[:3]
-			if f, exists := facultyInformation[codeB]; exists {
-				faculty = f
-			}
+			// Codes without a faculty keep an empty one and are reported once the scrape is done
+			c.Faculty, _ = faculties.Resolve(c.Code)
+			run.addOffering(c.Code, courseYearProg.CourseYearProg, "")
 
-			c.Faculty = faculty
-
-			if loaded, exists := processedCourses.Load(c.Code); exists {
-				if currCombined, ok := loaded.(Combined); ok {
-					processedCourses.Store(c.Code, Combined{
+			if loaded, exists := run.processedCourses.Load(c.Code); exists {
+				if currCombined, ok := loaded.(dto.Combined); ok {
+					run.processedCourses.Store(c.Code, dto.Combined{
 						Course:   c,
 						Exam:     currCombined.Exam,
 						Schedule: currCombined.Schedule,
 					})
 				}
 			} else {
-				processedCourses.Store(c.Code, Combined{
+				run.processedCourses.Store(c.Code, dto.Combined{
 					Course: c,
 				})
 			}
@@ -208,8 +523,8 @@ func getContentOfCourses(courseYearProgChan <-chan courseDetailParams, facultyIn
 	}
 }
 
-func getCourseTimetable(courseChan <-chan courseDetailParams, examChan chan<- examDetailParams) {
-	defer courseDetailWg.Done() // Decrement the counter when the goroutine completes
+func (run *semesterScrape) getCourseTimetable(wis *scraper.Scraper, courseChan <-chan courseDetailParams, examChan chan<- examDetailParams) {
+	defer run.courseDetailWg.Done() // Decrement the counter when the goroutine completes
 	for course := range courseChan {
 		fmt.Println("[WorkerB] Processing Course Schedule (", course.AcadYearSem, ", ", course.CourseYearProg, ")")
 		request := dto.CourseScheduleRequestDto{
@@ -218,9 +533,12 @@ func getCourseTimetable(courseChan <-chan courseDetailParams, examChan chan<- ex
 			BOption:     "CLoad",
 		}
 
-		res, err := scraper.GetCourseSchedule(request)
+		res, err := wis.GetCourseSchedule(request)
+		run.progress.Update(func(progress *dto.JobProgress) {
+			progress.ProgrammesDone++
+		})
 		if err != nil {
-			fmt.Println("Error in getCourseTimetable:", err)
+			run.fail("getCourseTimetable", err)
 			continue
 		}
 
@@ -229,16 +547,18 @@ func getCourseTimetable(courseChan <-chan courseDetailParams, examChan chan<- ex
 				continue
 			}
 
-			if loaded, exists := processedCourses.Load(c.Code); exists {
-				if currCombined, ok := loaded.(Combined); ok {
-					processedCourses.Store(c.Code, Combined{
+			run.addOffering(c.Code, course.CourseYearProg, c.Status)
+
+			if loaded, exists := run.processedCourses.Load(c.Code); exists {
+				if currCombined, ok := loaded.(dto.Combined); ok {
+					run.processedCourses.Store(c.Code, dto.Combined{
 						Course:   currCombined.Course,
 						Exam:     currCombined.Exam,
 						Schedule: c.Schedules,
 					})
 				}
 			} else {
-				processedCourses.Store(c.Code, Combined{
+				run.processedCourses.Store(c.Code, dto.Combined{
 					Schedule: c.Schedules,
 				})
 			}
@@ -253,8 +573,8 @@ func getCourseTimetable(courseChan <-chan courseDetailParams, examChan chan<- ex
 	}
 }
 
-func getExamSchedule(examChan <-chan examDetailParams) {
-	defer examDetailWg.Done() // Decrement the counter when the goroutine completes
+func (run *semesterScrape) getExamSchedule(wis *scraper.Scraper, examChan <-chan examDetailParams) {
+	defer run.examDetailWg.Done() // Decrement the counter when the goroutine completes
 	for course := range examChan {
 		fmt.Println("[WorkerC] Processing Exam Schedule (", course.AcadYearSem, ", ", course.Code, ")")
 		acadSem := strings.Split(course.AcadYearSem, "_")
@@ -268,23 +588,26 @@ func getExamSchedule(examChan <-chan examDetailParams) {
 			ExamSubject:  course.Code,
 		}
 
-		res, err := scraper.GetExamSchedule(request)
+		res, err := wis.GetExamSchedule(request)
+		run.progress.Update(func(progress *dto.JobProgress) {
+			progress.ExamsDone++
+		})
 		if err != nil {
-			fmt.Println("Error in getExamSchedule:", err)
+			run.fail("getExamSchedule", err)
 			continue
 		}
 
 		for _, exam := range res {
-			if loaded, exists := processedCourses.Load(exam.Code); exists {
-				if currCombined, ok := loaded.(Combined); ok {
-					processedCourses.Store(exam.Code, Combined{
+			if loaded, exists := run.processedCourses.Load(exam.Code); exists {
+				if currCombined, ok := loaded.(dto.Combined); ok {
+					run.processedCourses.Store(exam.Code, dto.Combined{
 						Course:   currCombined.Course,
 						Schedule: currCombined.Schedule,
 						Exam:     exam,
 					})
 				}
 			} else {
-				processedCourses.Store(exam.Code, Combined{
+				run.processedCourses.Store(exam.Code, dto.Combined{
 					Exam: exam,
 				})
 			}
@@ -292,35 +615,142 @@ func getExamSchedule(examChan <-chan examDetailParams) {
 	}
 }
 
-func populateFacultyInformation() (map[string]dto.Faculty, error) {
-	data, err := os.ReadFile("../data/faculty.json")
-	if err != nil {
-		fmt.Println("Error reading file:", err)
-		return nil, err
+// inScope reports whether courseYearProg, e.g. ACC;GA;1;F, starts with any of the prefixes in scope, an empty
+// scope covers every programme
+func inScope(courseYearProg string, scope []string) bool {
+	if len(scope) == 0 {
+		return true
 	}
 
-	var facultyData map[string]dto.Faculty
-	err = json.Unmarshal(data, &facultyData)
-	if err != nil {
-		fmt.Println("Error unmarshalling JSON:", err)
-		return nil, err
+	for _, prefix := range scope {
+		if strings.HasPrefix(courseYearProg, prefix) {
+			return true
+		}
 	}
+	return false
+}
 
-	output := make(map[string]dto.Faculty)
-	for keys, faculty := range facultyData {
-		for _, key := range strings.Split(keys, ";") {
-			output[key] = faculty
-		}
+// newFacultyLoader loads the faculty mapping from FACULTY_MAPPING_PATH, a file path or an http(s) URL, falling back to
+// the mapping embedded in the binary when it is not set, cannot be read or is invalid
+func newFacultyLoader() *faculty.Loader {
+	loader := faculty.NewLoader(os.Getenv("FACULTY_MAPPING_PATH"))
+
+	if _, err := loader.Reload(); err != nil {
+		fmt.Println("Error loading faculty mapping, using the embedded one until it is reloaded:", err)
 	}
 
-	return output, nil
+	status := loader.Status()
+	fmt.Println("Loaded faculty mapping (source =", status.Source, ", version =", status.Version, ")")
+	return loader
+}
+
+// runCommand runs a subcommand against the published data, without arguments main serves the scrape trigger instead
+func runCommand(storage utils.Storage, name string, args []string) error {
+	switch name {
+	case "scrape":
+		return runScrape(storage, args)
+	case "serve":
+		return runServe(storage, args)
+	case "graph":
+		return runGraph(storage, args)
+	case "exams":
+		return runExams(storage, args)
+	case "timetable":
+		return runTimetable(storage, args)
+	case "ics":
+		return runICS(storage, args)
+	case "eligibility":
+		return runEligibility(storage, args)
+	default:
+		return fmt.Errorf("unknown command: %s", name)
+	}
 }
 
 func main() {
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		executeScraper();
+	if err := godotenv.Load("../.env"); err != nil && !os.IsNotExist(err) {
+		log.Println("Error loading .env file:", err)
+	}
+
+	storage, err := utils.NewStorage(utils.StorageConfigFromEnv())
+	if err != nil {
+		log.Fatal("Error creating storage: ", err)
+	}
+
+	if len(os.Args) > 1 {
+		if err := runCommand(storage, os.Args[1], os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	wis, err := newScraper()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	webhooks := newWebhookStore()
+	dispatcher := webhook.NewDispatcher(webhooks, webhook.Config{})
+
+	faculties := newFacultyLoader()
+
+	resolve := func(selection string) ([]string, error) {
+		return resolveSemesters(wis, selection)
+	}
+	manager := jobs.NewManager(resolve, func(job dto.Job, progress *jobs.Progress) error {
+		return executeScraper(wis, storage, job.Semesters, scrapeOptions{
+			Scope:     job.Scope,
+			DryRun:    job.DryRun,
+			Progress:  progress,
+			Webhooks:  dispatcher,
+			Faculties: faculties,
+		})
 	})
 
+	// Starting scrapes and registering webhooks is limited to holders of the admin token
+	adminToken := os.Getenv("ADMIN_TOKEN")
+	if adminToken == "" {
+		fmt.Println("ADMIN_TOKEN is not set, /jobs and /webhooks are disabled")
+	}
+
+	http.HandleFunc("/jobs", requireAdmin(adminToken, handleSubmitJob(manager)))
+	http.HandleFunc("/jobs/", requireAdmin(adminToken, handleGetJob(manager)))
+	http.HandleFunc("/webhooks", requireAdmin(adminToken, handleWebhooks(webhooks)))
+	http.HandleFunc("/webhooks/", requireAdmin(adminToken, handleWebhook(webhooks)))
+	http.HandleFunc("/faculties", handleFaculties(faculties))
+	http.HandleFunc("/faculties/reload", handleReloadFaculties(faculties))
+
 	fmt.Println("Listening on port 8080")
 	http.ListenAndServe("127.0.0.1:8080", nil)
 }
+
+// newWebhookStore opens the registered webhooks, kept apart from the published modules as they hold secrets.
+// WEBHOOK_ALLOWED_HOSTS lists, comma-separated, the hosts in a private network webhooks may still be delivered to.
+func newWebhookStore() *webhook.Store {
+	dir := os.Getenv("WEBHOOKS_PATH")
+	if dir == "" {
+		dir = filepath.Join("..", "data", "webhooks")
+	}
+
+	var allowedHosts []string
+	if hosts := os.Getenv("WEBHOOK_ALLOWED_HOSTS"); hosts != "" {
+		allowedHosts = strings.Split(hosts, ",")
+	}
+	return webhook.NewStore(utils.NewLocalStorage(dir), allowedHosts)
+}
+
+// newScraper creates a scraper configured from the environment, recording or replaying WIS when a cassette mode is set
+func newScraper() (*scraper.Scraper, error) {
+	cassetteDir := os.Getenv("WIS_CASSETTE_DIR")
+	if cassetteDir == "" {
+		cassetteDir = filepath.Join("..", "data", "cassette")
+	}
+
+	transport, err := cassette.NewTransport(os.Getenv("WIS_CASSETTE_MODE"), cassetteDir, http.DefaultTransport)
+	if err != nil {
+		return nil, fmt.Errorf("[newScraper] Failed to create cassette transport: %v", err)
+	}
+
+	wisConfig := scraper.ConfigFromEnv()
+	wisConfig.Client = &http.Client{Transport: transport}
+	return scraper.New(wisConfig), nil
+}
@@ -0,0 +1,452 @@
+package main
+
+import (
+	"errors"
+	"ntumods/pkg/dto"
+	"ntumods/pkg/faculty"
+	"ntumods/pkg/scraper"
+	"ntumods/pkg/utils"
+	"ntumods/pkg/webhook"
+	"ntumods/pkg/webhooktest"
+	"ntumods/pkg/wistest"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestScrape starts a fake WIS and returns a scraper pointed at it together with an empty storage
+func newTestScrape(t *testing.T) (*wistest.Server, *scraper.Scraper, *utils.MemoryStorage) {
+	server := wistest.NewServer()
+	t.Cleanup(server.Close)
+
+	wis := scraper.New(scraper.Config{
+		BaseURL:    server.URL,
+		MaxRetries: 1,
+		RetryDelay: time.Millisecond,
+	})
+	return server, wis, utils.NewMemoryStorage()
+}
+
+func newTestResolver(t *testing.T) *faculty.Resolver {
+	loader := faculty.NewLoader("")
+	if _, err := loader.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	return loader.Resolver()
+}
+
+func runTestScrape(t *testing.T, server *wistest.Server, wis *scraper.Scraper, storage utils.Storage) {
+	err := scrapeSemester(wis, storage, storage, wistest.SEMESTER, newTestResolver(t), nil, scrapeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = server.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func getJSON(t *testing.T, storage utils.Storage, name string, v interface{}) {
+	if err := utils.GetJSON(storage, path.Join(wistest.SEMESTER, name), v); err != nil {
+		t.Fatalf("Failed to read %s: %v", name, err)
+	}
+}
+
+func getFile(t *testing.T, storage utils.Storage, name string) string {
+	data, err := storage.Get(path.Join(wistest.SEMESTER, name))
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", name, err)
+	}
+	return string(data)
+}
+
+func TestScrapeSemester(t *testing.T) {
+	server, wis, storage := newTestScrape(t)
+	runTestScrape(t, server, wis, storage)
+
+	var moduleList []dto.ModuleLite
+	getJSON(t, storage, dto.MODULE_LIST_FILE, &moduleList)
+
+	var codes []string
+	for _, m := range moduleList {
+		codes = append(codes, m.Code)
+	}
+	if !reflect.DeepEqual(codes, wistest.MODULES) {
+		t.Errorf("moduleList has %v, expected %v", codes, wistest.MODULES)
+	}
+
+	for _, code := range wistest.MODULES {
+		var module dto.Combined
+		getJSON(t, storage, code+".json", &module)
+
+		if module.Code != code || module.Title == "" {
+			t.Errorf("%s.json has code %q and title %q", code, module.Code, module.Title)
+		}
+		if module.Faculty.Code != "NBS" {
+			t.Errorf("%s.json has faculty %q, expected NBS", code, module.Faculty.Code)
+		}
+		if len(module.Programmes) == 0 {
+			t.Errorf("%s.json lists no programmes", code)
+		}
+	}
+
+	var ab1201 dto.Combined
+	getJSON(t, storage, "AB1201.json", &ab1201)
+	if len(ab1201.Schedule) == 0 {
+		t.Error("AB1201.json has no class schedule")
+	}
+	if ab1201.Exam.Code != "AB1201" {
+		t.Errorf("AB1201.json has the exam of %q", ab1201.Exam.Code)
+	}
+
+	// Only the schedule page of year 1 shows whether AB1201 is core
+	statuses := map[string]string{}
+	for _, o := range ab1201.Programmes {
+		statuses[o.Programme] = o.Status
+	}
+	if expected := map[string]string{"ACC;GA;1;F": dto.PROGRAMME_CORE, "ACC;GA;2;F": ""}; !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("AB1201.json has programme statuses %v, expected %v", statuses, expected)
+	}
+
+	var prerequisites dto.PrerequisiteGraph
+	getJSON(t, storage, dto.PREREQUISITE_GRAPH_FILE, &prerequisites)
+	if requires := prerequisites.Modules["AC1104"].Requires; !contains(requires, "AC1103") {
+		t.Errorf("AC1104 requires %v, expected AC1103 among them", requires)
+	}
+	if unlocks := prerequisites.Modules["AC1103"].Unlocks; !contains(unlocks, "AC1104") {
+		t.Errorf("AC1103 unlocks %v, expected AC1104 among them", unlocks)
+	}
+
+	var exclusions dto.ExclusionGraph
+	getJSON(t, storage, dto.EXCLUSION_GRAPH_FILE, &exclusions)
+	if excluded := exclusions.Modules["AB1201"]; !reflect.DeepEqual(excluded, []string{"BF2201"}) {
+		t.Errorf("AB1201 is exclusive with %v, expected [BF2201]", excluded)
+	}
+	if declaring := exclusions.Missing["BF2201"]; !reflect.DeepEqual(declaring, []string{"AB1201"}) {
+		t.Errorf("BF2201 is declared exclusive by %v, expected [AB1201]", declaring)
+	}
+
+	var unmatched []string
+	getJSON(t, storage, dto.UNMATCHED_FACULTIES_FILE, &unmatched)
+	if unmatched == nil || len(unmatched) != 0 {
+		t.Errorf("%s is %#v, expected an empty list", dto.UNMATCHED_FACULTIES_FILE, unmatched)
+	}
+
+	var changelog dto.Changelog
+	getJSON(t, storage, dto.CHANGES_FILE, &changelog)
+	if !changelog.Initial || len(changelog.Added) != len(wistest.MODULES) {
+		t.Errorf("changes.json is initial = %v with %d modules added, expected an initial scrape adding %d", changelog.Initial, len(changelog.Added), len(wistest.MODULES))
+	}
+
+	// The first scrape adds every module, which is left out of the feeds
+	for _, name := range []string{dto.FEED_FILE, path.Join(dto.FEEDS_DIR, "NBS.atom")} {
+		if atom := getFile(t, storage, name); strings.Contains(atom, "<entry>") {
+			t.Errorf("%s has entries after the first scrape:\n%s", name, atom)
+		}
+	}
+}
+
+func TestScrapeSemesterUnmatchedFaculties(t *testing.T) {
+	server, wis, storage := newTestScrape(t)
+
+	// Only the AB modules have a faculty
+	faculties, err := faculty.Parse([]byte(`{"AB": {"Faculty": "Nanyang Business School", "Code": "NBS"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = scrapeSemester(wis, storage, storage, wistest.SEMESTER, faculties, nil, scrapeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err = server.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	var unmatched []string
+	getJSON(t, storage, dto.UNMATCHED_FACULTIES_FILE, &unmatched)
+	if expected := []string{"AC1103", "AC1104", "AC2101"}; !reflect.DeepEqual(unmatched, expected) {
+		t.Errorf("%s is %v, expected %v", dto.UNMATCHED_FACULTIES_FILE, unmatched, expected)
+	}
+
+	// The next scrape resolves with a fresh resolver, replacing the report
+	runTestScrape(t, server, wis, storage)
+	getJSON(t, storage, dto.UNMATCHED_FACULTIES_FILE, &unmatched)
+	if len(unmatched) != 0 {
+		t.Errorf("%s is %v after every module matched", dto.UNMATCHED_FACULTIES_FILE, unmatched)
+	}
+}
+
+func TestScrapeSemesterChanges(t *testing.T) {
+	server, wis, storage := newTestScrape(t)
+	runTestScrape(t, server, wis, storage)
+
+	// Pretend that AB1201 had another title when it was last published
+	var ab1201 dto.Combined
+	getJSON(t, storage, "AB1201.json", &ab1201)
+	ab1201.Title = "CORPORATE FINANCE"
+	if err := utils.PutJSON(storage, path.Join(wistest.SEMESTER, "AB1201.json"), ab1201); err != nil {
+		t.Fatal(err)
+	}
+
+	runTestScrape(t, server, wis, storage)
+
+	var changelog dto.Changelog
+	getJSON(t, storage, dto.CHANGES_FILE, &changelog)
+	if changelog.Initial || len(changelog.Added) != 0 || len(changelog.Removed) != 0 {
+		t.Errorf("changes.json is initial = %v with %d added and %d removed, expected neither", changelog.Initial, len(changelog.Added), len(changelog.Removed))
+	}
+	if len(changelog.Changed) != 1 || changelog.Changed[0].Code != "AB1201" {
+		t.Fatalf("changes.json has changed %+v, expected only AB1201", changelog.Changed)
+	}
+
+	fields := changelog.Changed[0].Fields
+	if len(fields) != 1 || fields[0].Before != "CORPORATE FINANCE" || fields[0].After != "FINANCIAL MANAGEMENT" {
+		t.Errorf("AB1201 changed %+v, expected only its title", fields)
+	}
+
+	history, err := utils.LoadChangelogs(storage, wistest.SEMESTER, dto.FEED_HISTORY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 {
+		t.Errorf("%d changelogs were kept, expected 2", len(history))
+	}
+
+	for _, name := range []string{dto.FEED_FILE, path.Join(dto.FEEDS_DIR, "NBS.atom")} {
+		if atom := getFile(t, storage, name); strings.Count(atom, "<entry>") != 1 || !strings.Contains(atom, "urn:ntumods:2023_1:AB1201:") {
+			t.Errorf("%s does not have a single entry for AB1201:\n%s", name, atom)
+		}
+	}
+}
+
+func TestScrapeSemesterMissingModuleFile(t *testing.T) {
+	server, wis, storage := newTestScrape(t)
+	runTestScrape(t, server, wis, storage)
+
+	// AB1201 is in the published module list but its own file is gone
+	if err := storage.Delete(path.Join(wistest.SEMESTER, "AB1201.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	runTestScrape(t, server, wis, storage)
+
+	var changelog dto.Changelog
+	getJSON(t, storage, dto.CHANGES_FILE, &changelog)
+	if len(changelog.Added) != 1 || changelog.Added[0].Code != "AB1201" || len(changelog.Removed) != 0 {
+		t.Errorf("changes.json added %v and removed %v, expected AB1201 to be added again", changelog.Added, changelog.Removed)
+	}
+	getFile(t, storage, "AB1201.json")
+}
+
+// snapshot reads every file in storage
+func snapshot(t *testing.T, storage utils.Storage) map[string]string {
+	keys, err := storage.List("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := make(map[string]string)
+	for _, key := range keys {
+		data, err := storage.Get(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[key] = string(data)
+	}
+	return files
+}
+
+func TestScrapeSemesterFailedRequest(t *testing.T) {
+	tests := []string{dto.CONTENT_OF_COURSES, dto.CLASS_SCHEDULE, dto.EXAM_SCHEDULE}
+
+	for _, failing := range tests {
+		t.Run(failing, func(t *testing.T) {
+			server, wis, storage := newTestScrape(t)
+			runTestScrape(t, server, wis, storage)
+			published := snapshot(t, storage)
+
+			server.Fail(failing)
+			err := scrapeSemester(wis, storage, storage, wistest.SEMESTER, newTestResolver(t), nil, scrapeOptions{})
+			if err == nil || !strings.Contains(err.Error(), "not published") {
+				t.Errorf("scrapeSemester returned %v, expected an error as requests failed", err)
+			}
+
+			// Not even the module files of the programmes scraped are written
+			if !reflect.DeepEqual(snapshot(t, storage), published) {
+				t.Error("the published snapshot changed although requests failed")
+			}
+		})
+	}
+}
+
+func TestScrapeSemesterWebhooks(t *testing.T) {
+	server, wis, storage := newTestScrape(t)
+	runTestScrape(t, server, wis, storage)
+
+	receiver := webhooktest.NewReceiver("secret")
+	defer receiver.Close()
+
+	store := webhook.NewStore(utils.NewMemoryStorage(), []string{"127.0.0.1"})
+	if _, err := store.Add(dto.Webhook{URL: receiver.URL, Secret: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+	options := scrapeOptions{Webhooks: webhook.NewDispatcher(store, webhook.Config{MaxAttempts: 1})}
+
+	rename := func(title string) {
+		var ab1201 dto.Combined
+		getJSON(t, storage, "AB1201.json", &ab1201)
+		ab1201.Title = title
+		if err := utils.PutJSON(storage, path.Join(wistest.SEMESTER, "AB1201.json"), ab1201); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rename("CORPORATE FINANCE")
+	if err := scrapeSemester(wis, storage, storage, wistest.SEMESTER, newTestResolver(t), nil, options); err != nil {
+		t.Fatal(err)
+	}
+	options.Webhooks.Wait()
+
+	deliveries := receiver.Deliveries()
+	if len(deliveries) != 1 || len(deliveries[0].Changed) != 1 || deliveries[0].Changed[0].Code != "AB1201" {
+		t.Fatalf("deliveries are %+v, expected the change of AB1201", deliveries)
+	}
+
+	// A scrape missing the class schedules would announce every module as removed
+	rename("CORPORATE FINANCE")
+	server.Fail(dto.CLASS_SCHEDULE)
+	if err := scrapeSemester(wis, storage, storage, wistest.SEMESTER, newTestResolver(t), nil, options); err == nil {
+		t.Fatal("scrapeSemester succeeded although requests failed")
+	}
+	options.Webhooks.Wait()
+
+	if n := len(receiver.Deliveries()); n != 1 {
+		t.Errorf("%d deliveries after a failed scrape, expected none besides the first", n-1)
+	}
+	if err := receiver.Err(); err != nil {
+		t.Error(err)
+	}
+}
+
+// failingStorage fails to read and write the keys starting with prefix
+type failingStorage struct {
+	utils.Storage
+	prefix string
+}
+
+func (s failingStorage) Put(key string, data []byte) error {
+	if strings.HasPrefix(key, s.prefix) {
+		return errors.New("failing on purpose")
+	}
+	return s.Storage.Put(key, data)
+}
+
+func (s failingStorage) Get(key string) ([]byte, error) {
+	if strings.HasPrefix(key, s.prefix) {
+		return nil, errors.New("failing on purpose")
+	}
+	return s.Storage.Get(key)
+}
+
+func TestScrapeSemesterFeedsWithoutHistory(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+	}{
+		{"history not read", path.Join(wistest.SEMESTER, dto.CHANGES_DIR) + "/"},
+		{"changelog not stored", path.Join(wistest.SEMESTER, dto.CHANGES_DIR, time.Now().UTC().Format("2006"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, wis, storage := newTestScrape(t)
+			runTestScrape(t, server, wis, storage)
+			feeds := []string{dto.FEED_FILE, path.Join(dto.FEEDS_DIR, "NBS.atom")}
+			var before []string
+			for _, name := range feeds {
+				before = append(before, getFile(t, storage, name))
+			}
+
+			var ab1201 dto.Combined
+			getJSON(t, storage, "AB1201.json", &ab1201)
+			ab1201.Title = "CORPORATE FINANCE"
+			if err := utils.PutJSON(storage, path.Join(wistest.SEMESTER, "AB1201.json"), ab1201); err != nil {
+				t.Fatal(err)
+			}
+
+			failing := failingStorage{Storage: storage, prefix: tt.prefix}
+			runTestScrape(t, server, wis, failing)
+
+			for i, name := range feeds {
+				if atom := getFile(t, storage, name); atom != before[i] {
+					t.Errorf("%s was rebuilt from part of the history:\n%s", name, atom)
+				}
+			}
+		})
+	}
+}
+
+func contains(codes []string, code string) bool {
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func TestExecuteScraperCalendar(t *testing.T) {
+	tests := []struct {
+		name      string
+		calendars string
+		published bool
+	}{
+		{"configured", `{"2023_1": {"startDate": "2023-08-14", "recessAfterWeek": 7}}`, true},
+		{"missing", `{"2023_2": {"startDate": "2024-01-15", "recessAfterWeek": 7}}`, false},
+		{"without a start date", `{"2023_1": {"recessAfterWeek": 7}}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, wis, storage := newTestScrape(t)
+
+			filename := filepath.Join(t.TempDir(), "calendar.json")
+			if err := os.WriteFile(filename, []byte(tt.calendars), 0644); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv("ACADEMIC_CALENDAR_PATH", filename)
+
+			loader := faculty.NewLoader("")
+			if err := executeScraper(wis, storage, []string{wistest.SEMESTER}, scrapeOptions{Faculties: loader}); err != nil {
+				t.Fatal(err)
+			}
+			if err := server.Err(); err != nil {
+				t.Fatal(err)
+			}
+
+			// The semester is published whether or not it has a calendar
+			var modules []dto.ModuleLite
+			getJSON(t, storage, dto.MODULE_LIST_FILE, &modules)
+			if len(modules) != len(wistest.MODULES) {
+				t.Errorf("%d modules were published, expected %d", len(modules), len(wistest.MODULES))
+			}
+
+			calendar, err := utils.LoadCalendar(storage, wistest.SEMESTER)
+			if !tt.published {
+				if err != utils.ErrNotFound {
+					t.Errorf("LoadCalendar returned %+v, %v, expected no calendar to be published", calendar, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if calendar.AcadYearSem != wistest.SEMESTER || calendar.StartDate != "2023-08-14" || len(calendar.Weeks) != 13 || calendar.Exams == nil {
+				t.Errorf("the published calendar is %+v", calendar)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"ntumods/pkg/utils"
+	"ntumods/pkg/webhook"
+	"strings"
+)
+
+// runScrape scrapes the semesters picked by -semester once and exits, e.g. to backfill past semesters
+func runScrape(storage utils.Storage, args []string) error {
+	flags := flag.NewFlagSet("scrape", flag.ExitOnError)
+	semester := flags.String("semester", "latest", "semesters to scrape, e.g. 2023_1, 2022_2,2023_S, 2021_1..2023_1, all or latest")
+	scope := flags.String("scope", "", "comma separated prefixes of the programmes to scrape, e.g. ACC,CSC;GA;1;F")
+	dryRun := flags.Bool("dry-run", false, "scrape without publishing anything")
+	flags.Parse(args)
+
+	wis, err := newScraper()
+	if err != nil {
+		return err
+	}
+
+	semesters, err := resolveSemesters(wis, *semester)
+	if err != nil {
+		return err
+	}
+
+	faculties := newFacultyLoader()
+
+	dispatcher := webhook.NewDispatcher(newWebhookStore(), webhook.Config{})
+	// Deliver the changes of the semesters scraped before exiting, even when a later one failed
+	defer dispatcher.Wait()
+
+	options := scrapeOptions{
+		DryRun:    *dryRun,
+		Webhooks:  dispatcher,
+		Faculties: faculties,
+	}
+	if *scope != "" {
+		options.Scope = strings.Split(*scope, ",")
+	}
+
+	return executeScraper(wis, storage, semesters, options)
+}
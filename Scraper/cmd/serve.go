@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"ntumods/pkg/api"
+	"ntumods/pkg/utils"
+	"time"
+)
+
+// runServe serves the published modules over a read-only HTTP API, reloading them every -refresh
+func runServe(storage utils.Storage, args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", "127.0.0.1:8081", "address to listen on")
+	refresh := flags.Duration("refresh", 15*time.Minute, "how often to reload the published modules, 0 to never reload")
+	flags.Parse(args)
+
+	catalog := api.NewCatalog(storage)
+	if err := catalog.Load(); err != nil {
+		return err
+	}
+
+	if *refresh > 0 {
+		go func() {
+			for range time.Tick(*refresh) {
+				if err := catalog.Load(); err != nil {
+					fmt.Println("Error reloading modules:", err)
+				}
+			}
+		}()
+	}
+
+	server, err := api.NewServer(catalog)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Serving", len(catalog.Semesters()), "semesters on", *addr)
+	return http.ListenAndServe(*addr, server)
+}
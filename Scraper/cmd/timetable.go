@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"ntumods/pkg/dto"
+	"ntumods/pkg/graph"
+	"ntumods/pkg/planner"
+	"ntumods/pkg/utils"
+	"os"
+	"path"
+	"strings"
+)
+
+// runTimetable lists the clash-free index combinations of the module codes given as arguments
+func runTimetable(storage utils.Storage, args []string) error {
+	flags := flag.NewFlagSet("timetable", flag.ExitOnError)
+	semester := flags.String("semester", "", "published semester to read, e.g. 2023_1")
+	rank := flags.String("rank", "", "comma separated ranking criteria: fewest-days, no-early, least-gaps")
+	limit := flags.Int("limit", 20, "maximum number of combinations to print, 0 prints all")
+	maxCombinations := flags.Int("max-combinations", dto.TIMETABLE_MAX_COMBINATIONS, "stop searching after this many clash-free combinations, -1 searches them all")
+	flags.Parse(args)
+
+	if *semester == "" || flags.NArg() == 0 {
+		return fmt.Errorf("usage: timetable -semester 2023_1 [-rank fewest-days,no-early] [-max-combinations 10000] CODE [CODE...]")
+	}
+
+	modules, err := loadModules(storage, *semester, flags.Args())
+	if err != nil {
+		return err
+	}
+
+	warnExclusionConflicts(storage, *semester, modules)
+
+	options := planner.TimetableOptions{Limit: *limit, MaxCombinations: *maxCombinations}
+	if *rank != "" {
+		options.RankBy = strings.Split(*rank, ",")
+	}
+
+	timetables, err := planner.SolveTimetable(modules, options)
+	if err != nil {
+		return err
+	}
+
+	if timetables.Truncated {
+		fmt.Fprintf(os.Stderr, "Warning: stopped searching after %d combinations, the best ones may be missing, raise -max-combinations to search more\n", timetables.Found)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(timetables.Options)
+}
+
+// loadModules reads the published modules for codes, failing on the first one that was not published
+func loadModules(storage utils.Storage, semester string, codes []string) ([]dto.Combined, error) {
+	var modules []dto.Combined
+	for _, code := range codes {
+		code = strings.ToUpper(code)
+
+		module, err := utils.LoadModule(storage, semester, code)
+		if errors.Is(err, utils.ErrNotFound) {
+			return nil, fmt.Errorf("module %s was not found in %s", code, semester)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		module.Code = code
+		modules = append(modules, *module)
+	}
+	return modules, nil
+}
+
+// warnExclusionConflicts prints a warning for every pair of modules that cannot be taken together, using the
+// published exclusion graph or, for semesters published before it, the modules themselves
+func warnExclusionConflicts(storage utils.Storage, semester string, modules []dto.Combined) {
+	var exclusions dto.ExclusionGraph
+	err := utils.GetJSON(storage, path.Join(semester, dto.EXCLUSION_GRAPH_FILE), &exclusions)
+	if errors.Is(err, utils.ErrNotFound) {
+		courses := make([]dto.Course, 0, len(modules))
+		for _, m := range modules {
+			courses = append(courses, m.Course)
+		}
+		exclusions = graph.BuildExclusions(courses).Adjacency()
+	} else if err != nil {
+		fmt.Fprintln(os.Stderr, "Unable to check for mutually exclusive modules:", err)
+		return
+	}
+
+	codes := make([]string, 0, len(modules))
+	for _, m := range modules {
+		codes = append(codes, m.Code)
+	}
+
+	for _, conflict := range planner.FindExclusionConflicts(exclusions, codes) {
+		fmt.Fprintf(os.Stderr, "Warning: %s and %s are mutually exclusive\n", conflict.First, conflict.Second)
+	}
+}
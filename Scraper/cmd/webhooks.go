@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"ntumods/pkg/dto"
+	"ntumods/pkg/utils"
+	"ntumods/pkg/webhook"
+	"strings"
+)
+
+// handleWebhooks serves GET /webhooks, listing the registered webhooks without their secrets, and POST /webhooks,
+// registering the dto.Webhook in the body and returning it with its secret
+func handleWebhooks(store *webhook.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			webhooks, err := store.List()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			for i := range webhooks {
+				webhooks[i].Secret = ""
+			}
+			writeJSON(w, http.StatusOK, webhooks)
+		case http.MethodPost:
+			var request dto.Webhook
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				http.Error(w, fmt.Sprintf("invalid webhook: %v", err), http.StatusBadRequest)
+				return
+			}
+
+			registered, err := store.Add(dto.Webhook{
+				URL:       request.URL,
+				Secret:    request.Secret,
+				Codes:     request.Codes,
+				Faculties: request.Faculties,
+				Events:    request.Events,
+			})
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			w.Header().Set("Location", "/webhooks/"+registered.ID)
+			writeJSON(w, http.StatusCreated, registered)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// handleWebhook serves DELETE /webhooks/{id}, unregistering the webhook, and GET /webhooks/dead-letters, listing
+// the payloads that could not be delivered
+func handleWebhook(store *webhook.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/webhooks/")
+
+		switch {
+		case id == "dead-letters" && r.Method == http.MethodGet:
+			letters, err := store.DeadLetters()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, http.StatusOK, letters)
+		case r.Method == http.MethodDelete:
+			err := store.Remove(id)
+			if err == utils.ErrNotFound {
+				http.NotFound(w, r)
+				return
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Header().Set("Allow", "DELETE")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"ntumods/pkg/utils"
+	"ntumods/pkg/webhook"
+	"testing"
+)
+
+func TestHandleWebhooks(t *testing.T) {
+	store := webhook.NewStore(utils.NewMemoryStorage(), nil)
+	webhooks := requireAdmin(testAdminToken, handleWebhooks(store))
+
+	tests := []struct {
+		name    string
+		request *http.Request
+		status  int
+	}{
+		{"public address", adminRequest(http.MethodPost, "/webhooks", `{"url": "https://203.0.113.10/hook"}`), http.StatusCreated},
+		{"private address", adminRequest(http.MethodPost, "/webhooks", `{"url": "http://10.0.0.5/hook"}`), http.StatusBadRequest},
+		{"metadata endpoint", adminRequest(http.MethodPost, "/webhooks", `{"url": "http://169.254.169.254/latest/meta-data"}`), http.StatusBadRequest},
+		{"without the admin token", httptest.NewRequest(http.MethodPost, "/webhooks", nil), http.StatusUnauthorized},
+		{"list without the admin token", httptest.NewRequest(http.MethodGet, "/webhooks", nil), http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			webhooks(w, tt.request)
+			if w.Code != tt.status {
+				t.Errorf("status is %d, expected %d: %s", w.Code, tt.status, w.Body)
+			}
+		})
+	}
+
+	registered, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(registered) != 1 || registered[0].URL != "https://203.0.113.10/hook" {
+		t.Errorf("webhooks are %+v, expected only the public one", registered)
+	}
+}
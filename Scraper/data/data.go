@@ -0,0 +1,14 @@
+// Package data embeds the default data files, so that the binary does not depend on its working directory
+package data
+
+import _ "embed"
+
+// Faculty is the default faculty.json, mapping module code prefixes to faculties
+//
+//go:embed faculty.json
+var Faculty []byte
+
+// Calendar is the default calendar.json, giving the start of teaching week 1 of each semester
+//
+//go:embed calendar.json
+var Calendar []byte
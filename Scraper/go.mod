@@ -5,6 +5,8 @@ go 1.17
 require (
 	github.com/Azure/azure-storage-blob-go v0.15.0
 	github.com/antchfx/htmlquery v1.3.0
+	github.com/graphql-go/graphql v0.8.1
+	github.com/joho/godotenv v1.5.1
 	golang.org/x/net v0.24.0
 )
 
@@ -13,7 +15,6 @@ require (
 	github.com/antchfx/xpath v1.2.3 // indirect
 	github.com/golang/groupcache v0.0.0-20210331224755-41bb18bfe9da // indirect
 	github.com/google/uuid v1.6.0 // indirect
-	github.com/joho/godotenv v1.5.1 // indirect
 	github.com/mattn/go-ieproxy v0.0.1 // indirect
 	golang.org/x/sys v0.19.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
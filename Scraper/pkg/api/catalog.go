@@ -0,0 +1,261 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"ntumods/pkg/dto"
+	"ntumods/pkg/graph"
+	"ntumods/pkg/scraper"
+	"ntumods/pkg/search"
+	"ntumods/pkg/utils"
+	"path"
+	"strings"
+	"sync"
+)
+
+// Catalog holds every module published to a storage, it is safe for concurrent use
+type Catalog struct {
+	storage utils.Storage
+
+	mu        sync.RWMutex
+	semesters []string
+	modules   map[string][]dto.Combined
+	// byCode maps each semester to the position of every module in modules, keyed by module code
+	byCode     map[string]map[string]int
+	indexes    map[string]*search.Index
+	exclusions map[string]dto.ExclusionGraph
+	// versions identifies what was loaded for each semester, see semesterVersion
+	versions map[string]string
+}
+
+// ModuleFilter narrows down the modules of a semester, zero values match every module
+type ModuleFilter struct {
+	// Faculty matches the code or the name of the faculty, ignoring case
+	Faculty string
+	// AU matches the number of academic units
+	AU *float32
+	// Query matches part of the code, title or description, ignoring case
+	Query string
+}
+
+func NewCatalog(storage utils.Storage) *Catalog {
+	return &Catalog{
+		storage:    storage,
+		modules:    make(map[string][]dto.Combined),
+		byCode:     make(map[string]map[string]int),
+		indexes:    make(map[string]*search.Index),
+		exclusions: make(map[string]dto.ExclusionGraph),
+		versions:   make(map[string]string),
+	}
+}
+
+// Load reads every published semester from storage, replacing what was loaded before only once all of it is read.
+// Semesters that were not published again since the last Load are kept as they are.
+func (c *Catalog) Load() error {
+	semesters, err := utils.ListSemesters(c.storage)
+	if err != nil {
+		return err
+	}
+
+	// Load replaces these maps instead of changing them, so they can be read without holding the lock
+	c.mu.RLock()
+	loadedModules, loadedByCode, loadedIndexes, loadedExclusions, loadedVersions := c.modules, c.byCode, c.indexes, c.exclusions, c.versions
+	c.mu.RUnlock()
+
+	modules := make(map[string][]dto.Combined)
+	byCode := make(map[string]map[string]int)
+	indexes := make(map[string]*search.Index)
+	exclusions := make(map[string]dto.ExclusionGraph)
+	versions := make(map[string]string)
+	for _, semester := range semesters {
+		version, err := semesterVersion(c.storage, semester)
+		if err != nil {
+			return fmt.Errorf("[Catalog.Load] %v", err)
+		}
+		versions[semester] = version
+
+		if version == loadedVersions[semester] {
+			modules[semester] = loadedModules[semester]
+			byCode[semester] = loadedByCode[semester]
+			indexes[semester] = loadedIndexes[semester]
+			exclusions[semester] = loadedExclusions[semester]
+			continue
+		}
+
+		loaded, err := utils.LoadSemester(c.storage, semester)
+		if err != nil {
+			return fmt.Errorf("[Catalog.Load] %v", err)
+		}
+
+		modules[semester] = loaded
+		byCode[semester] = make(map[string]int)
+		for i, m := range loaded {
+			byCode[semester][m.Code] = i
+		}
+
+		courses := make([]dto.Course, 0, len(loaded))
+		for _, m := range loaded {
+			courses = append(courses, m.Course)
+		}
+
+		// Without a published search index, one is built from the modules
+		var index dto.SearchIndex
+		err = utils.GetJSON(c.storage, path.Join(semester, dto.SEARCH_INDEX_FILE), &index)
+		if err == utils.ErrNotFound {
+			index, err = search.Build(courses), nil
+		}
+		if err != nil {
+			return fmt.Errorf("[Catalog.Load] Failed to read search index of %s: %v", semester, err)
+		}
+		indexes[semester] = search.NewIndex(index)
+
+		// Without a published exclusion graph, one is built from the modules
+		var exclusionGraph dto.ExclusionGraph
+		err = utils.GetJSON(c.storage, path.Join(semester, dto.EXCLUSION_GRAPH_FILE), &exclusionGraph)
+		if err == utils.ErrNotFound {
+			exclusionGraph, err = graph.BuildExclusions(courses).Adjacency(), nil
+		}
+		if err != nil {
+			return fmt.Errorf("[Catalog.Load] Failed to read exclusion graph of %s: %v", semester, err)
+		}
+		exclusions[semester] = exclusionGraph
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.semesters = semesters
+	c.modules = modules
+	c.byCode = byCode
+	c.indexes = indexes
+	c.exclusions = exclusions
+	c.versions = versions
+
+	return nil
+}
+
+// semesterVersion hashes the module list of semester together with its changes.json, which every scrape rewrites,
+// so that a scrape changing only the classes or exams of modules still changes the version
+func semesterVersion(storage utils.Storage, semester string) (string, error) {
+	h := sha256.New()
+	for _, name := range []string{dto.MODULE_LIST_FILE, dto.CHANGES_FILE} {
+		data, err := storage.Get(path.Join(semester, name))
+		if err != nil && err != utils.ErrNotFound {
+			return "", fmt.Errorf("Failed to read %s of %s: %v", name, semester, err)
+		}
+		sum := sha256.Sum256(data)
+		h.Write(sum[:])
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Semesters returns every loaded semester, oldest first
+func (c *Catalog) Semesters() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]string(nil), c.semesters...)
+}
+
+// Latest returns the latest regular semester loaded, or the latest special term when there is no regular semester
+func (c *Catalog) Latest() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	latest := ""
+	for _, semester := range c.semesters {
+		if !scraper.IsSpecialTerm(semester) || latest == "" || scraper.IsSpecialTerm(latest) {
+			latest = semester
+		}
+	}
+	return latest
+}
+
+// Modules returns the modules of semester that match filter, false if the semester was not loaded
+func (c *Catalog) Modules(semester string, filter ModuleFilter) ([]dto.Combined, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	modules, exists := c.modules[semester]
+	if !exists {
+		return nil, false
+	}
+
+	matched := make([]dto.Combined, 0, len(modules))
+	for _, m := range modules {
+		if filter.matches(m) {
+			matched = append(matched, m)
+		}
+	}
+	return matched, true
+}
+
+// Exclusions returns the exclusion graph of semester, false if the semester was not loaded
+func (c *Catalog) Exclusions(semester string) (dto.ExclusionGraph, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	exclusions, exists := c.exclusions[semester]
+	return exclusions, exists
+}
+
+// Module returns a module of semester along with that semester, an empty semester means the latest semester
+// offering the module
+func (c *Catalog) Module(semester string, code string) (*dto.Combined, string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	code = strings.ToUpper(code)
+
+	if semester != "" {
+		m, exists := c.module(semester, code)
+		return m, semester, exists
+	}
+
+	for i := len(c.semesters) - 1; i >= 0; i-- {
+		if m, exists := c.module(c.semesters[i], code); exists {
+			return m, c.semesters[i], true
+		}
+	}
+	return nil, "", false
+}
+
+// Search searches the modules of semester, false if the semester was not loaded
+func (c *Catalog) Search(semester string, query string, limit int) ([]dto.SearchResult, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	index, exists := c.indexes[semester]
+	if !exists {
+		return nil, false
+	}
+	return index.Search(query, limit), true
+}
+
+func (c *Catalog) module(semester string, code string) (*dto.Combined, bool) {
+	i, exists := c.byCode[semester][code]
+	if !exists {
+		return nil, false
+	}
+	m := c.modules[semester][i]
+	return &m, true
+}
+
+func (f ModuleFilter) matches(m dto.Combined) bool {
+	if f.Faculty != "" && !strings.EqualFold(m.Faculty.Code, f.Faculty) && !strings.EqualFold(m.Faculty.Title, f.Faculty) {
+		return false
+	}
+
+	if f.AU != nil && m.AU != *f.AU {
+		return false
+	}
+
+	if f.Query != "" {
+		query := strings.ToLower(f.Query)
+		text := strings.ToLower(m.Code + "\n" + m.Title + "\n" + m.Description)
+		if !strings.Contains(text, query) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"ntumods/pkg/dto"
+	"ntumods/pkg/utils"
+	"path"
+	"testing"
+)
+
+// publish publishes modules to storage as the scraper would, leaving out the module files in unpublished
+func publish(t *testing.T, storage utils.Storage, semester string, modules []dto.Combined, unpublished ...string) {
+	skip := make(map[string]bool)
+	for _, code := range unpublished {
+		skip[code] = true
+	}
+
+	moduleList := make([]dto.ModuleLite, 0, len(modules))
+	for _, m := range modules {
+		moduleList = append(moduleList, dto.ModuleLite{Code: m.Code, Module: m.Title, AU: m.AU})
+		if skip[m.Code] {
+			continue
+		}
+		if err := utils.PutJSON(storage, path.Join(semester, m.Code+".json"), m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := utils.PutJSON(storage, path.Join(semester, dto.MODULE_LIST_FILE), moduleList); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testModule(code string, title string) dto.Combined {
+	var m dto.Combined
+	m.Code = code
+	m.Title = title
+	m.AU = 4
+	return m
+}
+
+func title(t *testing.T, catalog *Catalog, semester string, code string) string {
+	m, _, exists := catalog.Module(semester, code)
+	if !exists {
+		t.Fatalf("%s of %s is not loaded", code, semester)
+	}
+	return m.Title
+}
+
+func TestCatalogLoadSkipsMissingModule(t *testing.T) {
+	storage := utils.NewMemoryStorage()
+	publish(t, storage, "2023_1", []dto.Combined{
+		testModule("AB1201", "FINANCIAL MANAGEMENT"),
+		testModule("AC1103", "ACCOUNTING I"),
+	}, "AC1103")
+	publish(t, storage, "2023_2", []dto.Combined{testModule("AC1104", "ACCOUNTING II")})
+
+	catalog := NewCatalog(storage)
+	if err := catalog.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if title(t, catalog, "2023_1", "AB1201") != "FINANCIAL MANAGEMENT" {
+		t.Error("AB1201 was not loaded")
+	}
+	if _, _, exists := catalog.Module("2023_1", "AC1103"); exists {
+		t.Error("AC1103 was loaded although its file is missing")
+	}
+	if title(t, catalog, "2023_2", "AC1104") != "ACCOUNTING II" {
+		t.Error("the other semester was not loaded")
+	}
+}
+
+func TestCatalogLoadOnlyChangedSemesters(t *testing.T) {
+	storage := utils.NewMemoryStorage()
+	publish(t, storage, "2023_1", []dto.Combined{testModule("AB1201", "FINANCIAL MANAGEMENT")})
+	publish(t, storage, "2023_2", []dto.Combined{testModule("AC1104", "ACCOUNTING II")})
+
+	catalog := NewCatalog(storage)
+	if err := catalog.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A module file written without publishing the semester again is not picked up
+	if err := utils.PutJSON(storage, "2023_1/AB1201.json", testModule("AB1201", "CORPORATE FINANCE")); err != nil {
+		t.Fatal(err)
+	}
+	if err := catalog.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if got := title(t, catalog, "2023_1", "AB1201"); got != "FINANCIAL MANAGEMENT" {
+		t.Errorf("2023_1 was reloaded although its module list did not change, AB1201 is %q", got)
+	}
+
+	// A new module list reloads the semester
+	publish(t, storage, "2023_1", []dto.Combined{testModule("AB1201", "CORPORATE FINANCE"), testModule("AC1103", "ACCOUNTING I")})
+	if err := catalog.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if got := title(t, catalog, "2023_1", "AB1201"); got != "CORPORATE FINANCE" {
+		t.Errorf("2023_1 was not reloaded after its module list changed, AB1201 is %q", got)
+	}
+	if results, _ := catalog.Search("2023_1", "accounting", 10); len(results) != 1 || results[0].Code != "AC1103" {
+		t.Errorf("search of the reloaded semester returned %+v, expected AC1103", results)
+	}
+
+	// A scrape changing only classes leaves the module list as it was but writes a new changes.json
+	changed := testModule("AC1104", "ACCOUNTING II")
+	changed.Schedule = []dto.Schedule{{Index: "00001", DayOfWeek: "MON"}}
+	if err := utils.PutJSON(storage, "2023_2/AC1104.json", changed); err != nil {
+		t.Fatal(err)
+	}
+	if err := utils.PutJSON(storage, path.Join("2023_2", dto.CHANGES_FILE), dto.Changelog{Semester: "2023_2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := catalog.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if m, _, _ := catalog.Module("2023_2", "AC1104"); len(m.Schedule) != 1 {
+		t.Error("2023_2 was not reloaded after a new changes.json was published")
+	}
+
+	// Semesters that are no longer published are dropped
+	for _, key := range []string{"2023_2/AC1104.json", "2023_2/" + dto.MODULE_LIST_FILE, "2023_2/" + dto.CHANGES_FILE} {
+		if err := storage.Delete(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := catalog.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if semesters := catalog.Semesters(); len(semesters) != 1 || semesters[0] != "2023_1" {
+		t.Errorf("semesters are %v after 2023_2 was removed", semesters)
+	}
+}
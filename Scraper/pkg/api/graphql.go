@@ -0,0 +1,306 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"ntumods/pkg/dto"
+
+	"github.com/graphql-go/graphql"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// graphQLRequest is the body of POST /graphql, GET /graphql takes the same fields as query parameters
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// moduleResult is the source of the Module type, a module together with the semester it was loaded from
+type moduleResult struct {
+	dto.Combined
+	semester string
+}
+
+// scheduleIndex groups the classes of a module by index
+type scheduleIndex struct {
+	Index      string         `json:"index"`
+	IndexGroup string         `json:"indexGroup"`
+	Classes    []dto.Schedule `json:"classes"`
+}
+
+// moduleConnection is a page of the modules matching a query
+type moduleConnection struct {
+	TotalCount  int            `json:"totalCount"`
+	Offset      int            `json:"offset"`
+	Limit       int            `json:"limit"`
+	HasNextPage bool           `json:"hasNextPage"`
+	Items       []moduleResult `json:"items"`
+}
+
+// newGraphQLSchema builds the schema
+//
+//	type Query {
+//	  semesters: [String!]!
+//	  module(code: String!, semester: String): Module
+//	  modules(semester: String, faculty: String, au: Float, query: String, offset: Int = 0, limit: Int = 50): ModuleConnection!
+//	}
+//
+// where an omitted semester means the latest one, or for module the latest semester offering it
+func newGraphQLSchema(catalog *Catalog) (graphql.Schema, error) {
+	facultyType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Faculty",
+		Fields: graphql.Fields{
+			"name": &graphql.Field{Type: graphql.String, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(dto.Faculty).Title, nil
+			}},
+			"code": &graphql.Field{Type: graphql.String, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(dto.Faculty).Code, nil
+			}},
+		},
+	})
+
+	scheduleType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Schedule",
+		Fields: graphql.Fields{
+			"index":         &graphql.Field{Type: graphql.String},
+			"indexGroup":    &graphql.Field{Type: graphql.String},
+			"classType":     &graphql.Field{Type: graphql.String},
+			"dayOfWeek":     &graphql.Field{Type: graphql.String},
+			"startTime":     &graphql.Field{Type: graphql.String},
+			"endTime":       &graphql.Field{Type: graphql.String},
+			"venue":         &graphql.Field{Type: graphql.String},
+			"remarks":       &graphql.Field{Type: graphql.String},
+			"teachingWeeks": &graphql.Field{Type: graphql.NewList(graphql.Int)},
+		},
+	})
+
+	indexType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Index",
+		Fields: graphql.Fields{
+			"index":      &graphql.Field{Type: graphql.String},
+			"indexGroup": &graphql.Field{Type: graphql.String},
+			"classes":    &graphql.Field{Type: graphql.NewList(scheduleType)},
+		},
+	})
+
+	examType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ExamSchedule",
+		Fields: graphql.Fields{
+			"code":            &graphql.Field{Type: graphql.String},
+			"title":           &graphql.Field{Type: graphql.String},
+			"date":            &graphql.Field{Type: graphql.String},
+			"dayOfWeek":       &graphql.Field{Type: graphql.String},
+			"time":            &graphql.Field{Type: graphql.String},
+			"duration":        &graphql.Field{Type: graphql.String},
+			"start":           &graphql.Field{Type: graphql.String},
+			"end":             &graphql.Field{Type: graphql.String},
+			"durationMinutes": &graphql.Field{Type: graphql.Int},
+		},
+	})
+
+	programmeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Programme",
+		Fields: graphql.Fields{
+			"programme": &graphql.Field{Type: graphql.String},
+			"name":      &graphql.Field{Type: graphql.String},
+			"year":      &graphql.Field{Type: graphql.Int},
+			"status":    &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	course := func(t graphql.Output, get func(c dto.Course) interface{}) *graphql.Field {
+		return &graphql.Field{Type: t, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return get(p.Source.(moduleResult).Course), nil
+		}}
+	}
+
+	moduleType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Module",
+		Fields: graphql.Fields{
+			"semester": &graphql.Field{Type: graphql.String, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(moduleResult).semester, nil
+			}},
+			"code":                   course(graphql.String, func(c dto.Course) interface{} { return c.Code }),
+			"title":                  course(graphql.String, func(c dto.Course) interface{} { return c.Title }),
+			"au":                     course(graphql.Float, func(c dto.Course) interface{} { return c.AU }),
+			"description":            course(graphql.String, func(c dto.Course) interface{} { return c.Description }),
+			"prerequisite":           course(graphql.String, func(c dto.Course) interface{} { return c.Prerequisite }),
+			"mutuallyExclusive":      course(graphql.String, func(c dto.Course) interface{} { return c.MutuallyExclusive }),
+			"notAvailableTo":         course(graphql.String, func(c dto.Course) interface{} { return c.NotAvailableTo }),
+			"notAvailableToProgWith": course(graphql.String, func(c dto.Course) interface{} { return c.NotAvailableToProgWith }),
+			"gradeType":              course(graphql.String, func(c dto.Course) interface{} { return c.GradeType }),
+			"notAvailableAsUE":       course(graphql.String, func(c dto.Course) interface{} { return c.NotAvailableAsUE }),
+			"notAvailableAsPE":       course(graphql.String, func(c dto.Course) interface{} { return c.NotAvailableAsPE }),
+			"notOfferedAsBDE":        course(graphql.Boolean, func(c dto.Course) interface{} { return c.NotOfferedAsBDE }),
+			"faculty":                course(facultyType, func(c dto.Course) interface{} { return c.Faculty }),
+			"schedule": &graphql.Field{Type: graphql.NewList(scheduleType), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(moduleResult).Schedule, nil
+			}},
+			"indexes": &graphql.Field{Type: graphql.NewList(indexType), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return groupIndexes(p.Source.(moduleResult).Schedule), nil
+			}},
+			"programmes": &graphql.Field{Type: graphql.NewList(programmeType), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(moduleResult).Programmes, nil
+			}},
+			"exam": &graphql.Field{Type: examType, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				exam := p.Source.(moduleResult).Exam
+				if exam.Code == "" {
+					return nil, nil
+				}
+				return exam, nil
+			}},
+		},
+	})
+
+	connectionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ModuleConnection",
+		Fields: graphql.Fields{
+			"totalCount":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"offset":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"limit":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"hasNextPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"items":       &graphql.Field{Type: graphql.NewList(moduleType)},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"semesters": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return catalog.Semesters(), nil
+				},
+			},
+			"module": &graphql.Field{
+				Type: moduleType,
+				Args: graphql.FieldConfigArgument{
+					"code":     &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"semester": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					semester, _ := p.Args["semester"].(string)
+					code, _ := p.Args["code"].(string)
+
+					// Like GET /modules/{code}, look back through the semesters for a module not offered in the latest
+					module, semester, exists := catalog.Module(semester, code)
+					if !exists {
+						return nil, nil
+					}
+					return moduleResult{Combined: *module, semester: semester}, nil
+				},
+			},
+			"modules": &graphql.Field{
+				Type: graphql.NewNonNull(connectionType),
+				Args: graphql.FieldConfigArgument{
+					"semester": &graphql.ArgumentConfig{Type: graphql.String},
+					"faculty":  &graphql.ArgumentConfig{Type: graphql.String},
+					"au":       &graphql.ArgumentConfig{Type: graphql.Float},
+					"query":    &graphql.ArgumentConfig{Type: graphql.String},
+					"offset":   &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+					"limit":    &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPageSize},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					semester, _ := p.Args["semester"].(string)
+					offset, _ := p.Args["offset"].(int)
+					limit, _ := p.Args["limit"].(int)
+
+					if semester == "" {
+						semester = catalog.Latest()
+					}
+					if offset < 0 || limit < 0 || limit > maxPageSize {
+						return nil, fmt.Errorf("offset must not be negative and limit must be between 0 and %d", maxPageSize)
+					}
+
+					filter := ModuleFilter{}
+					filter.Faculty, _ = p.Args["faculty"].(string)
+					filter.Query, _ = p.Args["query"].(string)
+					if au, exists := p.Args["au"].(float64); exists {
+						units := float32(au)
+						filter.AU = &units
+					}
+
+					modules, exists := catalog.Modules(semester, filter)
+					if !exists {
+						return nil, fmt.Errorf("semester %s was not found", semester)
+					}
+
+					connection := moduleConnection{
+						TotalCount: len(modules),
+						Offset:     offset,
+						Limit:      limit,
+						Items:      []moduleResult{},
+					}
+					for i := offset; i < len(modules) && i < offset+limit; i++ {
+						connection.Items = append(connection.Items, moduleResult{Combined: modules[i], semester: semester})
+					}
+					connection.HasNextPage = offset+limit < len(modules)
+
+					return connection, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+// groupIndexes groups schedule by index, in the order each index first appears
+func groupIndexes(schedule []dto.Schedule) []scheduleIndex {
+	var indexes []scheduleIndex
+	positions := make(map[string]int)
+
+	for _, s := range schedule {
+		pos, exists := positions[s.Index]
+		if !exists {
+			pos = len(indexes)
+			positions[s.Index] = pos
+			indexes = append(indexes, scheduleIndex{Index: s.Index, IndexGroup: s.IndexGroup})
+		}
+		indexes[pos].Classes = append(indexes[pos].Classes, s)
+	}
+
+	return indexes
+}
+
+func (s *Server) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	var request graphQLRequest
+
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		request.Query = query.Get("query")
+		request.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				http.Error(w, fmt.Sprintf("invalid variables: %v", err), http.StatusBadRequest)
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, fmt.Sprintf("invalid GraphQL request: %v", err), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:         s.schema,
+		RequestString:  request.Query,
+		OperationName:  request.OperationName,
+		VariableValues: request.Variables,
+		Context:        r.Context(),
+	})
+
+	writeJSON(w, http.StatusOK, result)
+}
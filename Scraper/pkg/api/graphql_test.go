@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"ntumods/pkg/dto"
+	"ntumods/pkg/utils"
+	"strings"
+	"testing"
+)
+
+func TestGraphQLModuleMatchesREST(t *testing.T) {
+	storage := utils.NewMemoryStorage()
+	publish(t, storage, "2023_1", []dto.Combined{testModule("AB1201", "FINANCIAL MANAGEMENT"), testModule("AC1103", "ACCOUNTING I")})
+	// The latest semester no longer offers AB1201
+	publish(t, storage, "2023_2", []dto.Combined{testModule("AC1103", "ACCOUNTING I (2023/24)")})
+
+	catalog := NewCatalog(storage)
+	if err := catalog.Load(); err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(catalog)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		code     string
+		semester string
+		// expected is the semester the module is found in, empty when it is not found
+		expected string
+	}{
+		{"AB1201", "", "2023_1"},
+		{"ab1201", "", "2023_1"},
+		{"AC1103", "", "2023_2"},
+		{"AC1103", "2023_1", "2023_1"},
+		{"AB1201", "2023_2", ""},
+		{"ZZ9999", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code+" "+tt.semester, func(t *testing.T) {
+			query := `{ module(code: "` + tt.code + `"`
+			if tt.semester != "" {
+				query += `, semester: "` + tt.semester + `"`
+			}
+			query += `) { code title semester } }`
+
+			rec := httptest.NewRecorder()
+			server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(query), nil))
+
+			var response struct {
+				Data struct {
+					Module *struct {
+						Code     string `json:"code"`
+						Title    string `json:"title"`
+						Semester string `json:"semester"`
+					} `json:"module"`
+				} `json:"data"`
+				Errors []interface{} `json:"errors"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil || len(response.Errors) > 0 {
+				t.Fatalf("GraphQL returned %s", rec.Body.String())
+			}
+
+			path := "/modules/" + tt.code
+			if tt.semester != "" {
+				path += "?semester=" + tt.semester
+			}
+			rec = httptest.NewRecorder()
+			server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+			if tt.expected == "" {
+				if response.Data.Module != nil || rec.Code != http.StatusNotFound {
+					t.Errorf("GraphQL returned %+v and REST %d, expected neither to find the module", response.Data.Module, rec.Code)
+				}
+				return
+			}
+
+			var rest dto.Combined
+			if err := json.Unmarshal(rec.Body.Bytes(), &rest); err != nil {
+				t.Fatalf("REST returned %d %s", rec.Code, rec.Body.String())
+			}
+
+			module := response.Data.Module
+			if module == nil {
+				t.Fatalf("GraphQL did not find %s, REST found %s", tt.code, rest.Title)
+			}
+			if module.Semester != tt.expected {
+				t.Errorf("GraphQL found %s in %s, expected %s", tt.code, module.Semester, tt.expected)
+			}
+			if module.Code != rest.Code || module.Title != rest.Title {
+				t.Errorf("GraphQL returned %s %q, REST %s %q", module.Code, module.Title, rest.Code, rest.Title)
+			}
+			if !strings.EqualFold(module.Code, tt.code) {
+				t.Errorf("GraphQL returned %s for %s", module.Code, tt.code)
+			}
+		})
+	}
+}
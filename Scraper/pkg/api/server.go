@@ -0,0 +1,227 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"ntumods/pkg/dto"
+	"ntumods/pkg/eligibility"
+	"ntumods/pkg/planner"
+	"ntumods/pkg/scraper"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Server serves the modules of a Catalog over HTTP, every route is read-only
+type Server struct {
+	catalog *Catalog
+	schema  graphql.Schema
+	mux     *http.ServeMux
+}
+
+// NewServer routes
+//
+//	GET /semesters                        every published semester
+//	GET /semesters/{sem}/modules          modules of a semester, filtered by ?faculty=, ?au= and ?q=
+//	GET /semesters/{sem}/search           modules of a semester matching ?q=, best match first, at most ?limit=
+//	GET /semesters/{sem}/eligibility      modules a ?programme= in ?year= admitted in ?admissionYear= may take as
+//	                                      ?kind=ue|pe|bde, and why the others are excluded
+//	GET /semesters/{sem}/conflicts        pairs of the comma separated ?codes= that are mutually exclusive
+//	GET /modules/{code}                   a module in the latest semester offering it, or in ?semester=
+//	GET, POST /graphql                    the schema in graphql.go
+func NewServer(catalog *Catalog) (*Server, error) {
+	schema, err := newGraphQLSchema(catalog)
+	if err != nil {
+		return nil, fmt.Errorf("[NewServer] Failed to build GraphQL schema: %v", err)
+	}
+
+	s := &Server{catalog: catalog, schema: schema, mux: http.NewServeMux()}
+
+	s.mux.HandleFunc("/semesters", s.handleSemesters)
+	s.mux.HandleFunc("/semesters/", s.handleSemester)
+	s.mux.HandleFunc("/modules/", s.handleModule)
+	s.mux.HandleFunc("/graphql", s.handleGraphQL)
+
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// GraphQL queries may be POSTed, everything else is read with GET
+	if r.URL.Path != "/graphql" && r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleSemesters(w http.ResponseWriter, r *http.Request) {
+	semesters := s.catalog.Semesters()
+
+	summaries := make([]dto.SemesterSummary, 0, len(semesters))
+	for _, semester := range semesters {
+		modules, _ := s.catalog.Modules(semester, ModuleFilter{})
+		summaries = append(summaries, dto.SemesterSummary{
+			Semester:    semester,
+			SpecialTerm: scraper.IsSpecialTerm(semester),
+			Modules:     len(modules),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, summaries)
+}
+
+func (s *Server) handleSemester(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/semesters/"), "/")
+	if len(parts) != 2 || parts[0] == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch parts[1] {
+	case "modules":
+		s.handleSemesterModules(w, r, parts[0])
+	case "search":
+		s.handleSearch(w, r, parts[0])
+	case "eligibility":
+		s.handleEligibility(w, r, parts[0])
+	case "conflicts":
+		s.handleConflicts(w, r, parts[0])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) handleSemesterModules(w http.ResponseWriter, r *http.Request, semester string) {
+	query := r.URL.Query()
+	filter := ModuleFilter{
+		Faculty: query.Get("faculty"),
+		Query:   query.Get("q"),
+	}
+
+	if au := query.Get("au"); au != "" {
+		value, err := strconv.ParseFloat(au, 32)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("au %q is not a number", au), http.StatusBadRequest)
+			return
+		}
+		units := float32(value)
+		filter.AU = &units
+	}
+
+	modules, exists := s.catalog.Modules(semester, filter)
+	if !exists {
+		http.Error(w, fmt.Sprintf("semester %s was not found", semester), http.StatusNotFound)
+		return
+	}
+
+	moduleList := make([]dto.ModuleLite, 0, len(modules))
+	for _, m := range modules {
+		moduleList = append(moduleList, dto.ModuleLite{
+			Code:        m.Code,
+			Module:      m.Title,
+			AU:          m.AU,
+			Description: m.Description,
+			Faculty:     m.Faculty,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, moduleList)
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request, semester string) {
+	limit := 20
+	if value := r.URL.Query().Get("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit < 0 {
+			http.Error(w, fmt.Sprintf("limit %q is not a positive number", value), http.StatusBadRequest)
+			return
+		}
+	}
+
+	results, exists := s.catalog.Search(semester, r.URL.Query().Get("q"), limit)
+	if !exists {
+		http.Error(w, fmt.Sprintf("semester %s was not found", semester), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, results)
+}
+
+func (s *Server) handleEligibility(w http.ResponseWriter, r *http.Request, semester string) {
+	query := r.URL.Query()
+	student := eligibility.Student{Programme: query.Get("programme")}
+	if student.Programme == "" {
+		http.Error(w, "programme is required", http.StatusBadRequest)
+		return
+	}
+
+	for name, value := range map[string]*int{"year": &student.Year, "admissionYear": &student.AdmissionYear} {
+		if year := query.Get(name); year != "" {
+			var err error
+			if *value, err = strconv.Atoi(year); err != nil {
+				http.Error(w, fmt.Sprintf("%s %q is not a year", name, year), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	kind := strings.ToLower(query.Get("kind"))
+	if !eligibility.ValidKind(kind) {
+		http.Error(w, fmt.Sprintf("kind %q is not one of ue, pe or bde", kind), http.StatusBadRequest)
+		return
+	}
+
+	modules, exists := s.catalog.Modules(semester, ModuleFilter{})
+	if !exists {
+		http.Error(w, fmt.Sprintf("semester %s was not found", semester), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, eligibility.Filter(modules, student, kind))
+}
+
+func (s *Server) handleConflicts(w http.ResponseWriter, r *http.Request, semester string) {
+	exclusions, exists := s.catalog.Exclusions(semester)
+	if !exists {
+		http.Error(w, fmt.Sprintf("semester %s was not found", semester), http.StatusNotFound)
+		return
+	}
+
+	var codes []string
+	for _, code := range strings.Split(r.URL.Query().Get("codes"), ",") {
+		if code = strings.TrimSpace(code); code != "" {
+			codes = append(codes, code)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, planner.FindExclusionConflicts(exclusions, codes))
+}
+
+func (s *Server) handleModule(w http.ResponseWriter, r *http.Request) {
+	code := strings.TrimPrefix(r.URL.Path, "/modules/")
+	if code == "" || strings.Contains(code, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	semester := r.URL.Query().Get("semester")
+	module, _, exists := s.catalog.Module(semester, code)
+	if !exists {
+		http.Error(w, fmt.Sprintf("module %s was not found", code), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, module)
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		fmt.Println("Error writing response:", err)
+	}
+}
@@ -0,0 +1,207 @@
+package cassette
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	MODE_RECORD = "record"
+	MODE_REPLAY = "replay"
+)
+
+// Interaction is a single recorded request to NTU WIS together with its response
+type Interaction struct {
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	Form       url.Values  `json:"form"`
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body"`
+}
+
+// Recorder is a http.RoundTripper that forwards every request and saves the interaction to a cassette directory
+type Recorder struct {
+	dir  string
+	next http.RoundTripper
+}
+
+// Replayer is a http.RoundTripper that serves responses previously saved by a Recorder without touching the network
+type Replayer struct {
+	dir string
+}
+
+func NewRecorder(dir string, next http.RoundTripper) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Recorder{dir: dir, next: next}
+}
+
+func NewReplayer(dir string) *Replayer {
+	return &Replayer{dir: dir}
+}
+
+// NewTransport returns the transport to use for mode, an empty mode returns next unchanged
+func NewTransport(mode string, dir string, next http.RoundTripper) (http.RoundTripper, error) {
+	switch strings.ToLower(mode) {
+	case "":
+		return next, nil
+	case MODE_RECORD:
+		return NewRecorder(dir, next), nil
+	case MODE_REPLAY:
+		return NewReplayer(dir), nil
+	default:
+		return nil, fmt.Errorf("[cassette.NewTransport] Unknown cassette mode: %s", mode)
+	}
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	form, err := readForm(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	interaction := Interaction{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		Form:       form,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       string(body),
+	}
+
+	if err := r.save(interaction); err != nil {
+		fmt.Println("[Recorder] Error saving interaction:", err)
+	}
+
+	return resp, nil
+}
+
+func (r *Recorder) save(interaction Interaction) error {
+	data, err := json.MarshalIndent(interaction, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(r.dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	// Workers run concurrently, write to a temporary file first so a reader never sees a partial interaction
+	tmp, err := os.CreateTemp(r.dir, ".interaction-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filepath.Join(r.dir, fileName(interaction.Method, interaction.URL, interaction.Form)))
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	form, err := readForm(req)
+	if err != nil {
+		return nil, err
+	}
+
+	name := fileName(req.Method, req.URL.String(), form)
+	data, err := os.ReadFile(filepath.Join(r.dir, name))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("[Replayer] No recorded interaction for %s %s in semester %q (%s)", req.Method, req.URL, semester(form), form.Encode())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var interaction Interaction
+	if err := json.Unmarshal(data, &interaction); err != nil {
+		return nil, fmt.Errorf("[Replayer] Error unmarshalling %s: %v", name, err)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.StatusCode, http.StatusText(interaction.StatusCode)),
+		StatusCode:    interaction.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        interaction.Header,
+		Body:          io.NopCloser(strings.NewReader(interaction.Body)),
+		ContentLength: int64(len(interaction.Body)),
+		Request:       req,
+	}, nil
+}
+
+// readForm returns the form values of req while leaving its body intact for the next transport
+func readForm(req *http.Request) (url.Values, error) {
+	if req.Body == nil {
+		return url.Values{}, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	return url.ParseQuery(string(body))
+}
+
+// fileName identifies an interaction by its endpoint, semester and form values, e.g.
+// "aus_schedule.main_display1-2023_1-1a2b3c4d5e6f7a8b.json"
+func fileName(method string, rawURL string, form url.Values) string {
+	// url.Values.Encode sorts by key, so the same form always hashes the same regardless of field order
+	sum := sha256.Sum256([]byte(method + " " + rawURL + "\n" + form.Encode()))
+
+	endpoint := "request"
+	if u, err := url.Parse(rawURL); err == nil && path.Base(u.Path) != "/" && path.Base(u.Path) != "." {
+		endpoint = path.Base(u.Path)
+	}
+
+	if s := semester(form); s != "" {
+		endpoint += "-" + s
+	}
+
+	return endpoint + "-" + hex.EncodeToString(sum[:8]) + ".json"
+}
+
+// semester returns the semester a WIS form asks for, e.g. 2023_1, or "" when it names none
+func semester(form url.Values) string {
+	if acadsem := form.Get("acadsem"); acadsem != "" {
+		return strings.Replace(acadsem, ";", "_", 1)
+	}
+	if form.Get("p_exam_yr") != "" && form.Get("p_semester") != "" {
+		return form.Get("p_exam_yr") + "_" + form.Get("p_semester")
+	}
+	return ""
+}
@@ -0,0 +1,144 @@
+package cassette
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newWIS returns a server answering each form with its r_course_yr, or with status when r_course_yr is "fail"
+func newWIS(t *testing.T, status int) (*httptest.Server, *int) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		r.ParseForm()
+		w.Header().Set("Content-Type", "text/html")
+		if r.PostForm.Get("r_course_yr") == "fail" {
+			w.WriteHeader(status)
+			io.WriteString(w, "<html><body>Service Unavailable</body></html>")
+			return
+		}
+		io.WriteString(w, "<html><body>"+r.PostForm.Get("r_course_yr")+"</body></html>")
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func post(t *testing.T, transport http.RoundTripper, rawURL string, form url.Values) (*http.Response, string) {
+	client := &http.Client{Transport: transport}
+	resp, err := client.PostForm(rawURL, form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(body)
+}
+
+func scheduleForm(semester string, courseYearProg string) url.Values {
+	return url.Values{"acadsem": {semester}, "r_course_yr": {courseYearProg}, "boption": {"CLoad"}}
+}
+
+func TestRecordReplay(t *testing.T) {
+	server, requests := newWIS(t, http.StatusOK)
+	dir := t.TempDir()
+	endpoint := server.URL + "/webexe/owa/aus_schedule.main_display1"
+
+	_, recorded := post(t, NewRecorder(dir, nil), endpoint, scheduleForm("2023;1", "ACC;GA;1;F"))
+	if recorded != "<html><body>ACC;GA;1;F</body></html>" {
+		t.Fatalf("the recorder passed on %q", recorded)
+	}
+
+	names, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(names) != 1 || !strings.HasPrefix(filepath.Base(names[0]), "aus_schedule.main_display1-2023_1-") {
+		t.Fatalf("recorded %v, expected one aus_schedule.main_display1-2023_1-*.json", names)
+	}
+
+	// The same form in another order is served from the cassette without reaching WIS
+	resp, replayed := post(t, NewReplayer(dir), endpoint, url.Values{"boption": {"CLoad"}, "r_course_yr": {"ACC;GA;1;F"}, "acadsem": {"2023;1"}})
+	if replayed != recorded || resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/html" {
+		t.Errorf("replayed %d %q with header %v", resp.StatusCode, replayed, resp.Header)
+	}
+	if *requests != 1 {
+		t.Errorf("WIS received %d requests, expected 1", *requests)
+	}
+}
+
+func TestReplayMiss(t *testing.T) {
+	server, _ := newWIS(t, http.StatusOK)
+	dir := t.TempDir()
+	endpoint := server.URL + "/webexe/owa/aus_schedule.main_display1"
+
+	post(t, NewRecorder(dir, nil), endpoint, scheduleForm("2023;1", "ACC;GA;1;F"))
+
+	client := &http.Client{Transport: NewReplayer(dir)}
+	for _, form := range []url.Values{scheduleForm("2023;2", "ACC;GA;1;F"), scheduleForm("2023;1", "ACC;GA;2;F")} {
+		_, err := client.PostForm(endpoint, form)
+		if err == nil || !strings.Contains(err.Error(), "No recorded interaction") {
+			t.Errorf("replaying %s returned %v, expected no recorded interaction", form.Encode(), err)
+		}
+	}
+
+	_, err := client.PostForm(endpoint, scheduleForm("2023;2", "ACC;GA;1;F"))
+	if err == nil || !strings.Contains(err.Error(), `semester "2023_2"`) {
+		t.Errorf("the error %v does not name the semester", err)
+	}
+}
+
+func TestRecordErrorStatus(t *testing.T) {
+	server, _ := newWIS(t, http.StatusServiceUnavailable)
+	dir := t.TempDir()
+	endpoint := server.URL + "/webexe/owa/aus_schedule.main_display1"
+
+	resp, _ := post(t, NewRecorder(dir, nil), endpoint, scheduleForm("2023;1", "fail"))
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("the recorder passed on %d, expected 503", resp.StatusCode)
+	}
+
+	resp, body := post(t, NewReplayer(dir), endpoint, scheduleForm("2023;1", "fail"))
+	if resp.StatusCode != http.StatusServiceUnavailable || resp.Status != "503 Service Unavailable" {
+		t.Errorf("replayed status %q, expected 503 Service Unavailable", resp.Status)
+	}
+	if body != "<html><body>Service Unavailable</body></html>" {
+		t.Errorf("replayed body %q", body)
+	}
+}
+
+func TestFileName(t *testing.T) {
+	tests := []struct {
+		rawURL string
+		form   url.Values
+		prefix string
+	}{
+		{"https://wis.ntu.edu.sg/webexe/owa/aus_schedule.main_display1", url.Values{"acadsem": {"2023;1"}}, "aus_schedule.main_display1-2023_1-"},
+		{"https://wis.ntu.edu.sg/webexe/owa/AUS_SUBJ_CONT.main_display1", url.Values{"acadsem": {"2023_S"}}, "AUS_SUBJ_CONT.main_display1-2023_S-"},
+		{"https://wis.ntu.edu.sg/pls/webexe/exam_timetable_und.Get_detail", url.Values{"p_exam_yr": {"2023"}, "p_semester": {"2"}}, "exam_timetable_und.Get_detail-2023_2-"},
+		{"https://wis.ntu.edu.sg/", url.Values{}, "request-"},
+	}
+
+	for _, tt := range tests {
+		if name := fileName(http.MethodPost, tt.rawURL, tt.form); !strings.HasPrefix(name, tt.prefix) {
+			t.Errorf("fileName(%s, %s) is %s, expected it to start with %s", tt.rawURL, tt.form.Encode(), name, tt.prefix)
+		}
+	}
+}
+
+func TestNewTransport(t *testing.T) {
+	if transport, err := NewTransport("", "", http.DefaultTransport); err != nil || transport != http.DefaultTransport {
+		t.Errorf("an empty mode returned %v, %v", transport, err)
+	}
+	if transport, _ := NewTransport("RECORD", "dir", nil); transport == nil {
+		t.Error("record mode returned no transport")
+	}
+	if _, err := NewTransport("playback", "dir", nil); err == nil {
+		t.Error("an unknown mode was accepted")
+	}
+}
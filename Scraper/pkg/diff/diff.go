@@ -0,0 +1,235 @@
+package diff
+
+import (
+	"fmt"
+	"ntumods/pkg/dto"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Compare lists the modules added, removed and changed from previous to current, each sorted by code
+func Compare(previous []dto.Combined, current []dto.Combined) dto.Changelog {
+	changelog := dto.Changelog{
+		Initial: len(previous) == 0,
+		Added:   []dto.ModuleChange{},
+		Removed: []dto.ModuleChange{},
+		Changed: []dto.ModuleChange{},
+	}
+
+	before := make(map[string]dto.Combined)
+	for _, m := range previous {
+		before[m.Code] = m
+	}
+
+	after := make(map[string]dto.Combined)
+	for _, m := range current {
+		after[m.Code] = m
+
+		old, exists := before[m.Code]
+		if !exists {
+			changelog.Added = append(changelog.Added, summary(m))
+			continue
+		}
+
+		if change, changed := compareModule(old, m); changed {
+			changelog.Changed = append(changelog.Changed, change)
+		}
+	}
+
+	for _, m := range previous {
+		if _, exists := after[m.Code]; !exists {
+			changelog.Removed = append(changelog.Removed, summary(m))
+		}
+	}
+
+	for _, changes := range [][]dto.ModuleChange{changelog.Added, changelog.Removed, changelog.Changed} {
+		sort.Slice(changes, func(i, j int) bool { return changes[i].Code < changes[j].Code })
+	}
+
+	return changelog
+}
+
+func summary(m dto.Combined) dto.ModuleChange {
+	return dto.ModuleChange{Code: m.Code, Title: m.Title, Faculty: m.Faculty}
+}
+
+func compareModule(before dto.Combined, after dto.Combined) (dto.ModuleChange, bool) {
+	change := summary(after)
+
+	fields := [][3]string{
+		{"title", before.Title, after.Title},
+		{"au", formatAU(before.AU), formatAU(after.AU)},
+		{"description", before.Description, after.Description},
+		{"prerequisite", before.Prerequisite, after.Prerequisite},
+		{"mutuallyExclusive", before.MutuallyExclusive, after.MutuallyExclusive},
+		{"notAvailableTo", before.NotAvailableTo, after.NotAvailableTo},
+		{"notAvailableToProgWith", before.NotAvailableToProgWith, after.NotAvailableToProgWith},
+		{"gradeType", before.GradeType, after.GradeType},
+		{"notAvailableAsUE", before.NotAvailableAsUE, after.NotAvailableAsUE},
+		{"notAvailableAsPE", before.NotAvailableAsPE, after.NotAvailableAsPE},
+		{"notOfferedAsBDE", strconv.FormatBool(before.NotOfferedAsBDE), strconv.FormatBool(after.NotOfferedAsBDE)},
+		{"faculty", before.Faculty.Code, after.Faculty.Code},
+	}
+	for _, f := range fields {
+		if f[1] != f[2] {
+			change.Fields = append(change.Fields, dto.FieldChange{Field: f[0], Before: f[1], After: f[2]})
+		}
+	}
+
+	compareSchedules(&change, before.Schedule, after.Schedule)
+
+	if examChanged(before.Exam, after.Exam) {
+		change.Exam = &dto.ExamChange{}
+		if before.Exam.Code != "" {
+			exam := before.Exam
+			change.Exam.Before = &exam
+		}
+		if after.Exam.Code != "" {
+			exam := after.Exam
+			change.Exam.After = &exam
+		}
+	}
+
+	changed := len(change.Fields) > 0 || len(change.AddedIndexes) > 0 || len(change.RemovedIndexes) > 0 ||
+		len(change.Classes) > 0 || change.Exam != nil
+	return change, changed
+}
+
+// classKey identifies a class within an index, n counting the classes of the same type and group in order
+type classKey struct {
+	classType  string
+	indexGroup string
+	n          int
+}
+
+func compareSchedules(change *dto.ModuleChange, before []dto.Schedule, after []dto.Schedule) {
+	oldIndexes := byIndex(before)
+	newIndexes := byIndex(after)
+
+	for _, index := range sortedKeys(newIndexes) {
+		if _, exists := oldIndexes[index]; !exists {
+			change.AddedIndexes = append(change.AddedIndexes, index)
+		}
+	}
+
+	for _, index := range sortedKeys(oldIndexes) {
+		newClasses, exists := newIndexes[index]
+		if !exists {
+			change.RemovedIndexes = append(change.RemovedIndexes, index)
+			continue
+		}
+
+		oldClasses := oldIndexes[index]
+		for _, key := range sortedClassKeys(oldClasses, newClasses) {
+			o, inOld := oldClasses[key]
+			n, inNew := newClasses[key]
+
+			switch {
+			case !inNew:
+				change.Classes = append(change.Classes, classChange(index, key, "class", describeClass(o), ""))
+			case !inOld:
+				change.Classes = append(change.Classes, classChange(index, key, "class", "", describeClass(n)))
+			default:
+				fields := [][3]string{
+					{"dayOfWeek", o.DayOfWeek, n.DayOfWeek},
+					{"time", o.StartTime + "-" + o.EndTime, n.StartTime + "-" + n.EndTime},
+					{"venue", o.Venue, n.Venue},
+					{"teachingWeeks", formatWeeks(o.TeachingWeeks), formatWeeks(n.TeachingWeeks)},
+					{"remarks", o.Remarks, n.Remarks},
+				}
+				for _, f := range fields {
+					if f[1] != f[2] {
+						change.Classes = append(change.Classes, classChange(index, key, f[0], f[1], f[2]))
+					}
+				}
+			}
+		}
+	}
+}
+
+func byIndex(schedule []dto.Schedule) map[string]map[classKey]dto.Schedule {
+	indexes := make(map[string]map[classKey]dto.Schedule)
+	for _, s := range schedule {
+		classes, exists := indexes[s.Index]
+		if !exists {
+			classes = make(map[classKey]dto.Schedule)
+			indexes[s.Index] = classes
+		}
+
+		key := classKey{classType: s.ClassType, indexGroup: s.IndexGroup}
+		for {
+			if _, taken := classes[key]; !taken {
+				break
+			}
+			key.n++
+		}
+		classes[key] = s
+	}
+	return indexes
+}
+
+func sortedKeys(indexes map[string]map[classKey]dto.Schedule) []string {
+	keys := make([]string, 0, len(indexes))
+	for key := range indexes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedClassKeys(a map[classKey]dto.Schedule, b map[classKey]dto.Schedule) []classKey {
+	seen := make(map[classKey]bool)
+	var keys []classKey
+	for _, classes := range []map[classKey]dto.Schedule{a, b} {
+		for key := range classes {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].classType != keys[j].classType {
+			return keys[i].classType < keys[j].classType
+		}
+		if keys[i].indexGroup != keys[j].indexGroup {
+			return keys[i].indexGroup < keys[j].indexGroup
+		}
+		return keys[i].n < keys[j].n
+	})
+	return keys
+}
+
+func classChange(index string, key classKey, field string, before string, after string) dto.ClassChange {
+	return dto.ClassChange{
+		Index:      index,
+		ClassType:  key.classType,
+		IndexGroup: key.indexGroup,
+		Field:      field,
+		Before:     before,
+		After:      after,
+	}
+}
+
+func describeClass(s dto.Schedule) string {
+	return fmt.Sprintf("%s %s-%s %s", s.DayOfWeek, s.StartTime, s.EndTime, s.Venue)
+}
+
+func examChanged(before dto.ExamSchedule, after dto.ExamSchedule) bool {
+	return before.Code != after.Code || before.Date != after.Date || before.Time != after.Time ||
+		before.Duration != after.Duration || before.Start != after.Start || before.End != after.End
+}
+
+func formatAU(au float32) string {
+	return strconv.FormatFloat(float64(au), 'f', -1, 32)
+}
+
+func formatWeeks(weeks []int) string {
+	parts := make([]string, len(weeks))
+	for i, w := range weeks {
+		parts[i] = strconv.Itoa(w)
+	}
+	return strings.Join(parts, ",")
+}
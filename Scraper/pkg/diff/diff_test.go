@@ -0,0 +1,153 @@
+package diff
+
+import (
+	"ntumods/pkg/dto"
+	"reflect"
+	"testing"
+)
+
+func testModule(code string, title string, schedule ...dto.Schedule) dto.Combined {
+	var m dto.Combined
+	m.Code = code
+	m.Title = title
+	m.AU = 3
+	m.Schedule = schedule
+	return m
+}
+
+func lecture(index string, day string, start string, venue string) dto.Schedule {
+	return dto.Schedule{Index: index, ClassType: "LEC", IndexGroup: "LE", DayOfWeek: day, StartTime: start, EndTime: "1020",
+		Venue: venue, TeachingWeeks: []int{1, 2, 3}}
+}
+
+func codes(changes []dto.ModuleChange) []string {
+	list := []string{}
+	for _, c := range changes {
+		list = append(list, c.Code)
+	}
+	return list
+}
+
+func TestCompareModules(t *testing.T) {
+	previous := []dto.Combined{testModule("AC1103", ""), testModule("AB1201", ""), testModule("AC2101", "")}
+	current := []dto.Combined{testModule("HE9091", ""), testModule("AB1201", ""), testModule("AC1104", "")}
+
+	changelog := Compare(previous, current)
+	if changelog.Initial {
+		t.Error("a changelog with a previous snapshot is initial")
+	}
+	if added := codes(changelog.Added); !reflect.DeepEqual(added, []string{"AC1104", "HE9091"}) {
+		t.Errorf("added %v, expected [AC1104 HE9091]", added)
+	}
+	if removed := codes(changelog.Removed); !reflect.DeepEqual(removed, []string{"AC1103", "AC2101"}) {
+		t.Errorf("removed %v, expected [AC1103 AC2101]", removed)
+	}
+	if changed := codes(changelog.Changed); len(changed) != 0 {
+		t.Errorf("changed %v, expected none", changed)
+	}
+
+	initial := Compare(nil, current)
+	if !initial.Initial || len(initial.Added) != 3 || len(initial.Removed) != 0 {
+		t.Errorf("the first changelog is %+v, expected it to be initial and add every module", initial)
+	}
+}
+
+func TestCompareChanges(t *testing.T) {
+	exam := dto.ExamSchedule{Code: "AB1201", Date: "27 November 2023", Time: "9.00 am", Duration: "2 hr",
+		Start: "2023-11-27T09:00:00+08:00", End: "2023-11-27T11:00:00+08:00"}
+	movedExam := exam
+	movedExam.Date, movedExam.Start, movedExam.End = "28 November 2023", "2023-11-28T09:00:00+08:00", "2023-11-28T11:00:00+08:00"
+
+	tests := []struct {
+		name     string
+		before   dto.Combined
+		after    dto.Combined
+		expected *dto.ModuleChange
+	}{
+		{
+			"unchanged",
+			testModule("AB1201", "FINANCIAL MANAGEMENT", lecture("00101", "MON", "0830", "LT1")),
+			testModule("AB1201", "FINANCIAL MANAGEMENT", lecture("00101", "MON", "0830", "LT1")),
+			nil,
+		},
+		{
+			"fields",
+			func() dto.Combined {
+				m := testModule("AB1201", "CORPORATE FINANCE")
+				m.Prerequisite = "AB1202"
+				return m
+			}(),
+			func() dto.Combined {
+				m := testModule("AB1201", "FINANCIAL MANAGEMENT")
+				m.AU = 4
+				m.NotOfferedAsBDE = true
+				return m
+			}(),
+			&dto.ModuleChange{Fields: []dto.FieldChange{
+				{Field: "title", Before: "CORPORATE FINANCE", After: "FINANCIAL MANAGEMENT"},
+				{Field: "au", Before: "3", After: "4"},
+				{Field: "prerequisite", Before: "AB1202", After: ""},
+				{Field: "notOfferedAsBDE", Before: "false", After: "true"},
+			}},
+		},
+		{
+			"indexes",
+			testModule("AB1201", "FINANCIAL MANAGEMENT", lecture("00101", "MON", "0830", "LT1"), lecture("00102", "TUE", "0830", "LT1")),
+			testModule("AB1201", "FINANCIAL MANAGEMENT", lecture("00102", "TUE", "0830", "LT1"), lecture("00104", "THU", "0830", "LT1"), lecture("00103", "WED", "0830", "LT1")),
+			&dto.ModuleChange{AddedIndexes: []string{"00103", "00104"}, RemovedIndexes: []string{"00101"}},
+		},
+		{
+			"class moved",
+			testModule("AB1201", "FINANCIAL MANAGEMENT", lecture("00101", "MON", "0830", "LT1")),
+			testModule("AB1201", "FINANCIAL MANAGEMENT", lecture("00101", "WED", "0930", "LT2")),
+			&dto.ModuleChange{Classes: []dto.ClassChange{
+				{Index: "00101", ClassType: "LEC", IndexGroup: "LE", Field: "dayOfWeek", Before: "MON", After: "WED"},
+				{Index: "00101", ClassType: "LEC", IndexGroup: "LE", Field: "time", Before: "0830-1020", After: "0930-1020"},
+				{Index: "00101", ClassType: "LEC", IndexGroup: "LE", Field: "venue", Before: "LT1", After: "LT2"},
+			}},
+		},
+		{
+			// The second lecture of the same type and group is told apart from the first by its order
+			"class added",
+			testModule("AB1201", "FINANCIAL MANAGEMENT", lecture("00101", "MON", "0830", "LT1")),
+			testModule("AB1201", "FINANCIAL MANAGEMENT", lecture("00101", "MON", "0830", "LT1"), lecture("00101", "THU", "0830", "LT1")),
+			&dto.ModuleChange{Classes: []dto.ClassChange{
+				{Index: "00101", ClassType: "LEC", IndexGroup: "LE", Field: "class", Before: "", After: "THU 0830-1020 LT1"},
+			}},
+		},
+		{
+			"exam moved",
+			func() dto.Combined { m := testModule("AB1201", "FINANCIAL MANAGEMENT"); m.Exam = exam; return m }(),
+			func() dto.Combined { m := testModule("AB1201", "FINANCIAL MANAGEMENT"); m.Exam = movedExam; return m }(),
+			&dto.ModuleChange{Exam: &dto.ExamChange{Before: &exam, After: &movedExam}},
+		},
+		{
+			"exam dropped",
+			func() dto.Combined { m := testModule("AB1201", "FINANCIAL MANAGEMENT"); m.Exam = exam; return m }(),
+			testModule("AB1201", "FINANCIAL MANAGEMENT"),
+			&dto.ModuleChange{Exam: &dto.ExamChange{Before: &exam}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changelog := Compare([]dto.Combined{tt.before}, []dto.Combined{tt.after})
+
+			if tt.expected == nil {
+				if len(changelog.Changed) != 0 {
+					t.Errorf("changed %+v, expected nothing", changelog.Changed)
+				}
+				return
+			}
+
+			if len(changelog.Changed) != 1 {
+				t.Fatalf("changed %+v, expected AB1201", changelog.Changed)
+			}
+			expected := *tt.expected
+			expected.Code, expected.Title = "AB1201", tt.after.Title
+			if got := changelog.Changed[0]; !reflect.DeepEqual(got, expected) {
+				t.Errorf("the change is\n%+v\nexpected\n%+v", got, expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package dto
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+var weekdayOffsets = map[string]int{
+	"MON": 0,
+	"TUE": 1,
+	"WED": 2,
+	"THU": 3,
+	"FRI": 4,
+	"SAT": 5,
+	"SUN": 6,
+}
+
+// WeekStart returns the Monday of teaching week in Asia/Singapore, skipping over the recess week
+func (c AcademicCalendar) WeekStart(week int) (time.Time, error) {
+	start, err := time.ParseInLocation(dateLayout, c.StartDate, SINGAPORE)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid start date %q of %s: %v", c.StartDate, c.AcadYearSem, err)
+	}
+
+	if start.Weekday() != time.Monday {
+		return time.Time{}, fmt.Errorf("start date %s of %s is not a Monday", c.StartDate, c.AcadYearSem)
+	}
+
+	if week < 1 || week > c.numTeachingWeeks() {
+		return time.Time{}, fmt.Errorf("%s has no teaching week %d", c.AcadYearSem, week)
+	}
+
+	offset := week - 1
+	if c.RecessAfterWeek > 0 && week > c.RecessAfterWeek {
+		offset++
+	}
+
+	return start.AddDate(0, 0, offset*7), nil
+}
+
+// ClassDates returns the date of every class held on dayOfWeek, e.g. "MON", during the given teaching weeks
+func (c AcademicCalendar) ClassDates(dayOfWeek string, weeks []int) ([]time.Time, error) {
+	dayOffset, exists := weekdayOffsets[strings.ToUpper(dayOfWeek)]
+	if !exists {
+		return nil, fmt.Errorf("unknown day %q", dayOfWeek)
+	}
+
+	sorted := append([]int(nil), weeks...)
+	sort.Ints(sorted)
+
+	dates := make([]time.Time, 0, len(sorted))
+	for _, week := range sorted {
+		weekStart, err := c.WeekStart(week)
+		if err != nil {
+			return nil, err
+		}
+		dates = append(dates, weekStart.AddDate(0, 0, dayOffset))
+	}
+
+	return dates, nil
+}
+
+// Expand returns a copy of c with the dates of every teaching week and of the recess week filled in
+func (c AcademicCalendar) Expand() (AcademicCalendar, error) {
+	c.Weeks = nil
+	c.Recess = nil
+
+	for week := 1; week <= c.numTeachingWeeks(); week++ {
+		start, err := c.WeekStart(week)
+		if err != nil {
+			return c, err
+		}
+
+		c.Weeks = append(c.Weeks, CalendarWeek{
+			Week:  week,
+			Start: start.Format(dateLayout),
+			End:   start.AddDate(0, 0, 6).Format(dateLayout),
+		})
+
+		if week == c.RecessAfterWeek {
+			recess := start.AddDate(0, 0, 7)
+			c.Recess = &CalendarPeriod{
+				Start: recess.Format(dateLayout),
+				End:   recess.AddDate(0, 0, 6).Format(dateLayout),
+			}
+		}
+	}
+
+	return c, nil
+}
+
+func (c AcademicCalendar) numTeachingWeeks() int {
+	if c.NumTeachingWeeks > 0 {
+		return c.NumTeachingWeeks
+	}
+	return NUM_TEACHING_WEEKS
+}
+
+// ExamPeriod returns the dates of the first and last of exams, or nil if none of them has a start and end
+func ExamPeriod(exams []ExamSchedule) *CalendarPeriod {
+	var first, last time.Time
+	for _, exam := range exams {
+		start, errStart := time.Parse(time.RFC3339, exam.Start)
+		end, errEnd := time.Parse(time.RFC3339, exam.End)
+		if errStart != nil || errEnd != nil {
+			continue
+		}
+
+		if first.IsZero() || start.Before(first) {
+			first = start
+		}
+		if last.IsZero() || end.After(last) {
+			last = end
+		}
+	}
+
+	if first.IsZero() {
+		return nil
+	}
+
+	return &CalendarPeriod{
+		Start: first.In(SINGAPORE).Format(dateLayout),
+		End:   last.In(SINGAPORE).Format(dateLayout),
+	}
+}
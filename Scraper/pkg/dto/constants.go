@@ -2,11 +2,14 @@ package dto
 
 import "time"
 
+const WIS_BASE_URL = "https://wis.ntu.edu.sg"
+
+// Paths are relative to WIS_BASE_URL
 const (
-	CONTENT_OF_COURSES_INIT = "https://wis.ntu.edu.sg/webexe/owa/AUS_SUBJ_CONT.main_display"
-	CONTENT_OF_COURSES      = "https://wis.ntu.edu.sg/webexe/owa/AUS_SUBJ_CONT.main_display1"
-	CLASS_SCHEDULE          = "https://wis.ntu.edu.sg/webexe/owa/aus_schedule.main_display1"
-	EXAM_SCHEDULE           = "https://wis.ntu.edu.sg/pls/webexe/exam_timetable_und.Get_detail"
+	CONTENT_OF_COURSES_INIT = "/webexe/owa/AUS_SUBJ_CONT.main_display"
+	CONTENT_OF_COURSES      = "/webexe/owa/AUS_SUBJ_CONT.main_display1"
+	CLASS_SCHEDULE          = "/webexe/owa/aus_schedule.main_display1"
+	EXAM_SCHEDULE           = "/pls/webexe/exam_timetable_und.Get_detail"
 )
 
 const (
@@ -18,8 +21,117 @@ const (
 
 const MAX_RETRIES = 3
 const RETRY_DELAY = 5 * time.Second
+const NUM_TEACHING_WEEKS = 13
+const REQUEST_TIMEOUT = 60 * time.Second
+const USER_AGENT = "ntumods-scraper"
 
 const (
 	ACCOUNT_NAME   = "ntumodssa"
 	CONTAINER_NAME = "ntumodssc"
 )
+
+const (
+	STORAGE_AZURE  = "azure"
+	STORAGE_LOCAL  = "local"
+	STORAGE_MEMORY = "memory"
+	STORAGE_S3     = "s3"
+)
+
+const S3_DEFAULT_REGION = "us-east-1"
+
+const (
+	PREREQ_AND       = "and"
+	PREREQ_OR        = "or"
+	PREREQ_MODULE    = "module"
+	PREREQ_GRADE     = "grade"
+	PREREQ_STANDING  = "standing"
+	PREREQ_ADMISSION = "admission"
+	PREREQ_TEXT      = "text"
+)
+
+// GRADES are the letter grades awarded by NTU from best to worst
+var GRADES = []string{"A+", "A", "A-", "B+", "B", "B-", "C+", "C", "D+", "D", "F"}
+
+// Artifacts published alongside <semester>/<code>.json
+const (
+	MODULE_LIST_FILE        = "moduleList.json"
+	PREREQUISITE_GRAPH_FILE = "prerequisiteGraph.json"
+	EXCLUSION_GRAPH_FILE    = "exclusionGraph.json"
+	CALENDAR_FILE           = "calendar.json"
+	SEARCH_INDEX_FILE       = "searchIndex.json"
+	CHANGES_FILE            = "changes.json"
+	// CHANGES_DIR keeps the changelogs of the last FEED_HISTORY runs, named after the time of the run
+	CHANGES_DIR = "changes"
+	// FEED_FILE is the Atom feed of the semester, FEEDS_DIR holds one feed per faculty named <faculty code>.atom
+	FEED_FILE = "feed.atom"
+	FEEDS_DIR = "feeds"
+	// FEED_HISTORY is the number of runs the feeds cover
+	FEED_HISTORY = 20
+	// PROGRAMMES_FILE lists the programmes of the semester, PROGRAMMES_DIR holds the modules of each
+	PROGRAMMES_FILE = "programmes.json"
+	PROGRAMMES_DIR  = "programmes"
+	// UNMATCHED_FACULTIES_FILE lists the module codes of the semester that no faculty prefix matched
+	UNMATCHED_FACULTIES_FILE = "unmatchedFaculties.json"
+)
+
+// Kinds of elective a module can be taken as, outside of the programme offering it
+const (
+	ELECTIVE_UE  = "ue"
+	ELECTIVE_PE  = "pe"
+	ELECTIVE_BDE = "bde"
+)
+
+// Status of a module within a programme
+const (
+	PROGRAMME_CORE     = "core"
+	PROGRAMME_ELECTIVE = "elective"
+)
+
+// SINGAPORE is the time zone of every date and time published by NTU, Singapore does not observe daylight saving
+var SINGAPORE = time.FixedZone("Asia/Singapore", 8*60*60)
+
+// Criteria to rank timetable options by, applied in the order given
+const (
+	RANK_FEWEST_DAYS = "fewest-days"
+	RANK_NO_EARLY    = "no-early"
+	RANK_LEAST_GAPS  = "least-gaps"
+)
+
+// TIMETABLE_MAX_COMBINATIONS is how many clash-free timetable options are searched before giving up on the rest
+const TIMETABLE_MAX_COMBINATIONS = 10000
+
+// Statuses of a scrape job
+const (
+	JOB_QUEUED    = "queued"
+	JOB_RUNNING   = "running"
+	JOB_SUCCEEDED = "succeeded"
+	JOB_FAILED    = "failed"
+)
+
+// Finished jobs are forgotten once they are older than JOB_RETENTION or more than MAX_FINISHED_JOBS have finished since
+const (
+	JOB_RETENTION     = 24 * time.Hour
+	MAX_FINISHED_JOBS = 100
+)
+
+// Kinds of change a webhook can be filtered to
+const (
+	WEBHOOK_ADDED    = "added"
+	WEBHOOK_REMOVED  = "removed"
+	WEBHOOK_DETAILS  = "details"
+	WEBHOOK_SCHEDULE = "schedule"
+	WEBHOOK_EXAM     = "exam"
+)
+
+// Headers of a webhook delivery, the signature is the hex HMAC-SHA256 of "<timestamp>.<body>" keyed by the secret
+const (
+	WEBHOOK_SIGNATURE_HEADER = "X-Ntumods-Signature"
+	WEBHOOK_TIMESTAMP_HEADER = "X-Ntumods-Timestamp"
+	WEBHOOK_DELIVERY_HEADER  = "X-Ntumods-Delivery"
+)
+
+const WEBHOOK_MAX_ATTEMPTS = 5
+const WEBHOOK_RETRY_DELAY = 2 * time.Second
+
+// FACULTY_MAPPING_MAX_SIZE is the largest faculty mapping read from a URL, data/faculty.json is a few kilobytes
+const FACULTY_MAPPING_MAX_SIZE = 1 << 20
@@ -0,0 +1,61 @@
+package dto
+
+// IsSatisfiedBy reports whether student meets the requirement described by n.
+// PREREQ_TEXT nodes cannot be checked and are never satisfied, a nil node is always satisfied.
+func (n *PrerequisiteNode) IsSatisfiedBy(student PrerequisiteStudent) bool {
+	if n == nil {
+		return true
+	}
+
+	switch n.Type {
+	case PREREQ_AND:
+		for _, child := range n.Children {
+			if !child.IsSatisfiedBy(student) {
+				return false
+			}
+		}
+		return true
+	case PREREQ_OR:
+		for _, child := range n.Children {
+			if child.IsSatisfiedBy(student) {
+				return true
+			}
+		}
+		return false
+	case PREREQ_MODULE:
+		if n.Corequisite && student.Taking[n.Code] {
+			return true
+		}
+		grade, taken := student.Completed[n.Code]
+		return taken && meetsGrade(grade, n.Grade)
+	case PREREQ_GRADE:
+		grade, taken := student.Completed[n.Subject]
+		return taken && meetsGrade(grade, n.Grade)
+	case PREREQ_STANDING:
+		return student.Year >= n.Year
+	case PREREQ_ADMISSION:
+		return student.AdmissionYear >= n.FromYear && (n.ToYear == 0 || student.AdmissionYear <= n.ToYear)
+	default:
+		return false
+	}
+}
+
+// meetsGrade reports whether grade is at least minimum, an empty grade counts as a pass
+func meetsGrade(grade string, minimum string) bool {
+	if minimum == "" {
+		return grade != "F"
+	}
+	if grade == "" {
+		return false
+	}
+	return gradeRank(grade) <= gradeRank(minimum)
+}
+
+func gradeRank(grade string) int {
+	for i, g := range GRADES {
+		if g == grade {
+			return i
+		}
+	}
+	return len(GRADES)
+}
@@ -1,19 +1,27 @@
 package dto
 
 type Course struct {
-	Code                   string  `json:"code"`
-	Title                  string  `json:"title"`
-	AU                     float32 `json:"au"`
-	Prerequisite           string  `json:"prerequisite"`
-	MutuallyExclusive      string  `json:"mutually_exclusive"`
-	NotAvailableTo         string  `json:"not_available_to"`
-	NotAvailableToProgWith string  `json:"not_available_to_prog_with"`
-	GradeType              string  `json:"grade_type"`
-	NotAvailableAsUE       string  `json:"not_available_as_ue"`
-	NotAvailableAsPE       string  `json:"not_available_as_pe"`
-	Description            string  `json:"description"`
-	Faculty                Faculty `json:"faculty"`
-	NotOfferedAsBDE        bool    `json:"notOfferedAsBDE"`
+	Code                   string            `json:"code"`
+	Title                  string            `json:"title"`
+	AU                     float32           `json:"au"`
+	Prerequisite           string            `json:"prerequisite"`
+	PrerequisiteTree       *PrerequisiteNode `json:"prerequisite_tree,omitempty"`
+	MutuallyExclusive      string            `json:"mutually_exclusive"`
+	NotAvailableTo         string            `json:"not_available_to"`
+	NotAvailableToProgWith string            `json:"not_available_to_prog_with"`
+	GradeType              string            `json:"grade_type"`
+	NotAvailableAsUE       string            `json:"not_available_as_ue"`
+	NotAvailableAsPE       string            `json:"not_available_as_pe"`
+	Description            string            `json:"description"`
+	Faculty                Faculty           `json:"faculty"`
+	NotOfferedAsBDE        bool              `json:"notOfferedAsBDE"`
+	// MutuallyExclusiveCodes are the module codes in MutuallyExclusive, see parser.ParseMutuallyExclusive
+	MutuallyExclusiveCodes []string `json:"mutually_exclusive_codes,omitempty"`
+	// The restrictions above split into programme codes, see parser.ParseProgrammeCodes
+	NotAvailableToCodes         []string `json:"not_available_to_codes,omitempty"`
+	NotAvailableToProgWithCodes []string `json:"not_available_to_prog_with_codes,omitempty"`
+	NotAvailableAsUECodes       []string `json:"not_available_as_ue_codes,omitempty"`
+	NotAvailableAsPECodes       []string `json:"not_available_as_pe_codes,omitempty"`
 }
 
 type CourseListRequestDto struct {
@@ -52,6 +60,51 @@ type CourseExamScheduleRequestDto struct {
 type CourseSchedules struct {
 	AcadYearSem    []string
 	CourseYearProg []string
+	// ProgrammeNames maps each CourseYearProg to the name it is listed under, e.g. "Accountancy Year 1 (GA)"
+	ProgrammeNames map[string]string
+}
+
+// Combined is everything scraped about a module, it is published as <semester>/<code>.json
+type Combined struct {
+	Course
+	Exam       ExamSchedule        `json:"exam"`
+	Schedule   []Schedule          `json:"schedule"`
+	Programmes []ProgrammeOffering `json:"programmes"`
+}
+
+// ProgrammeOffering is a programme and year that offers a module
+type ProgrammeOffering struct {
+	// Programme is the CourseYearProg the module was listed under, e.g. "ACC;GA;1;F"
+	Programme string `json:"programme"`
+	Name      string `json:"name"`
+	Year      int    `json:"year,omitempty"`
+	// Status is PROGRAMME_CORE or PROGRAMME_ELECTIVE, empty if the page does not say
+	Status string `json:"status,omitempty"`
+}
+
+// ProgrammeSummary describes a programme in <semester>/programmes.json
+type ProgrammeSummary struct {
+	Programme string `json:"programme"`
+	Name      string `json:"name"`
+	Year      int    `json:"year,omitempty"`
+	Modules   int    `json:"modules"`
+}
+
+// ProgrammeIndex lists the modules a programme and year takes, it is published as <semester>/programmes/<key>.json
+type ProgrammeIndex struct {
+	Programme string            `json:"programme"`
+	Name      string            `json:"name"`
+	Year      int               `json:"year,omitempty"`
+	Modules   []ProgrammeModule `json:"modules"`
+}
+
+// ProgrammeModule is a module in a ProgrammeIndex
+type ProgrammeModule struct {
+	Code    string  `json:"code"`
+	Title   string  `json:"title"`
+	AU      float32 `json:"au"`
+	Faculty Faculty `json:"faculty"`
+	Status  string  `json:"status,omitempty"`
 }
 
 // ModuleLite is a lightweight representation of a module
@@ -63,10 +116,19 @@ type ModuleLite struct {
 	Faculty     Faculty `json:"faculty"`
 }
 
+// SemesterSummary describes a published semester
+type SemesterSummary struct {
+	Semester    string `json:"semester"`
+	SpecialTerm bool   `json:"specialTerm"`
+	Modules     int    `json:"modules"`
+}
+
 // Module is a structure containing the module code, title, and the semesters which it is offered
 type Module struct {
-	Code      string
-	Title     string
+	Code  string
+	Title string
+	// Status is whether the module is core or elective to the programme, empty if the page does not say
+	Status    string
 	Schedules []Schedule `json:"schedules"`
 }
 
@@ -89,9 +151,317 @@ type ExamSchedule struct {
 	Code      string `json:"code"`
 	Title     string `json:"title"`
 	Duration  string `json:"duration"`
+	// Start and End are RFC3339 timestamps in Asia/Singapore, parsed from Date, Time and Duration
+	Start           string `json:"start"`
+	End             string `json:"end"`
+	DurationMinutes int    `json:"durationMinutes"`
 }
 
 type Faculty struct {
 	Title string `json:"Faculty"`
 	Code  string `json:"Code"`
 }
+
+// PrerequisiteNode is a node of the boolean expression parsed from Course.Prerequisite
+type PrerequisiteNode struct {
+	// Type is one of the PREREQ_* constants
+	Type string `json:"type"`
+	// Code is the module code of a PREREQ_MODULE node
+	Code string `json:"code,omitempty"`
+	// Grade is the minimum grade required for a PREREQ_MODULE or PREREQ_GRADE node
+	Grade string `json:"grade,omitempty"`
+	// Corequisite marks a PREREQ_MODULE node that may also be taken in the same semester
+	Corequisite bool `json:"corequisite,omitempty"`
+	// Subject is what a PREREQ_GRADE node requires the grade in, e.g. "H2 Mathematics"
+	Subject string `json:"subject,omitempty"`
+	// Year is the study year of a PREREQ_STANDING node
+	Year int `json:"year,omitempty"`
+	// FromYear and ToYear bound the admission year of a PREREQ_ADMISSION node, ToYear is 0 when open ended
+	FromYear int                 `json:"fromYear,omitempty"`
+	ToYear   int                 `json:"toYear,omitempty"`
+	Text     string              `json:"text,omitempty"`
+	Children []*PrerequisiteNode `json:"children,omitempty"`
+}
+
+// PrerequisiteStudent is what a PrerequisiteNode is evaluated against
+type PrerequisiteStudent struct {
+	// Completed maps the module codes taken to the grade obtained, an empty grade counts as a pass
+	Completed map[string]string
+	// Taking lists the module codes taken in the same semester, which satisfy corequisites
+	Taking        map[string]bool
+	Year          int
+	AdmissionYear int
+}
+
+// PrerequisiteGraph is the JSON adjacency export of the module dependency graph
+type PrerequisiteGraph struct {
+	Modules map[string]PrerequisiteGraphNode `json:"modules"`
+	// Cycles lists groups of modules that require each other, directly or indirectly
+	Cycles [][]string `json:"cycles"`
+	// Missing maps module codes that are required but were not scraped to the modules requiring them
+	Missing map[string][]string `json:"missing"`
+}
+
+type PrerequisiteGraphNode struct {
+	Title    string   `json:"title"`
+	Requires []string `json:"requires"`
+	Unlocks  []string `json:"unlocks"`
+}
+
+// ExclusionGraph is the JSON export of the modules that are mutually exclusive with each other
+type ExclusionGraph struct {
+	// Modules maps every module code to the modules it is mutually exclusive with, both ways even when only one
+	// of the two declares it
+	Modules map[string][]string `json:"modules"`
+	// OneSided lists the declarations that the other module, although scraped, does not make in return
+	OneSided []OneSidedExclusion `json:"oneSided"`
+	// Missing maps module codes that are declared exclusive but were not scraped to the modules declaring them
+	Missing map[string][]string `json:"missing"`
+}
+
+// OneSidedExclusion is a module declared mutually exclusive with another that does not declare it back
+type OneSidedExclusion struct {
+	Module   string `json:"module"`
+	Excludes string `json:"excludes"`
+}
+
+// ExclusionConflict is a pair of selected modules that are mutually exclusive
+type ExclusionConflict struct {
+	First  string `json:"first"`
+	Second string `json:"second"`
+}
+
+// ExamClash is a pair of exams that overlap, or that fall on the same day one after the other
+type ExamClash struct {
+	First  ExamSchedule `json:"first"`
+	Second ExamSchedule `json:"second"`
+	// OverlapMinutes is set for overlapping exams, GapMinutes for back-to-back exams
+	OverlapMinutes int `json:"overlapMinutes,omitempty"`
+	GapMinutes     int `json:"gapMinutes,omitempty"`
+}
+
+type ExamClashReport struct {
+	Clashes    []ExamClash `json:"clashes"`
+	BackToBack []ExamClash `json:"backToBack"`
+	// NoExam lists the modules without an exam, or whose exam time could not be parsed
+	NoExam []string `json:"noExam"`
+}
+
+// TimetableOption is a clash-free choice of one index for each module
+type TimetableOption struct {
+	// Indexes maps each module code to the chosen index
+	Indexes map[string]string `json:"indexes"`
+	// CampusDays counts the days with at least one class that is not online
+	CampusDays int `json:"campusDays"`
+	// EarlyClasses counts the weekly classes starting before 9am
+	EarlyClasses int `json:"earlyClasses"`
+	// GapMinutes is the idle time between classes summed over the week
+	GapMinutes int `json:"gapMinutes"`
+}
+
+// TimetableResult is the best ranked of the timetable options searched
+type TimetableResult struct {
+	Options []TimetableOption `json:"options"`
+	// Found counts the clash-free options searched, Options holds the best of them
+	Found int `json:"found"`
+	// Truncated is set when the search stopped at the maximum number of combinations, there are more options
+	// that were not ranked
+	Truncated bool `json:"truncated"`
+}
+
+// AcademicCalendar maps the teaching weeks of a semester to dates, every date is formatted as 2006-01-02
+type AcademicCalendar struct {
+	AcadYearSem string `json:"acadYearSem"`
+	// StartDate is the Monday of teaching week 1
+	StartDate string `json:"startDate"`
+	// RecessAfterWeek is the teaching week followed by the recess week, 0 if there is no recess
+	RecessAfterWeek int `json:"recessAfterWeek"`
+	// NumTeachingWeeks is the number of teaching weeks, 13 when left out
+	NumTeachingWeeks int `json:"numTeachingWeeks,omitempty"`
+	// Weeks and Recess are filled in from the fields above by Expand
+	Weeks  []CalendarWeek  `json:"weeks,omitempty"`
+	Recess *CalendarPeriod `json:"recess,omitempty"`
+	Exams  *CalendarPeriod `json:"exams,omitempty"`
+}
+
+type CalendarWeek struct {
+	Week  int    `json:"week"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+type CalendarPeriod struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// JobRequest is the body of POST /jobs
+type JobRequest struct {
+	// Semester selects the semesters to scrape, e.g. 2023_1, 2022_2,2023_S, all or latest
+	Semester string `json:"semester"`
+	// Scope limits the scrape to the programmes starting with any of these prefixes, e.g. ACC or ACC;GA;1;F
+	Scope []string `json:"scope,omitempty"`
+	// DryRun scrapes without publishing anything
+	DryRun bool `json:"dryRun"`
+}
+
+// Job is a scrape started through POST /jobs, every time is formatted as RFC3339
+type Job struct {
+	ID string `json:"id"`
+	// Selection is the semester of the JobRequest, Semesters are the semesters it resolved to once the job started
+	Selection  string      `json:"selection"`
+	Semesters  []string    `json:"semesters"`
+	Scope      []string    `json:"scope,omitempty"`
+	DryRun     bool        `json:"dryRun"`
+	Status     string      `json:"status"`
+	Error      string      `json:"error,omitempty"`
+	CreatedAt  string      `json:"createdAt"`
+	StartedAt  string      `json:"startedAt,omitempty"`
+	FinishedAt string      `json:"finishedAt,omitempty"`
+	Progress   JobProgress `json:"progress"`
+}
+
+type JobProgress struct {
+	// Semester is the semester being scraped
+	Semester        string `json:"semester,omitempty"`
+	SemestersDone   int    `json:"semestersDone"`
+	ProgrammesTotal int    `json:"programmesTotal"`
+	ProgrammesDone  int    `json:"programmesDone"`
+	ExamsDone       int    `json:"examsDone"`
+	ModulesScraped  int    `json:"modulesScraped"`
+}
+
+// SearchIndex is an inverted index over the code, title and description of the modules of a semester
+type SearchIndex struct {
+	// Modules and Titles list the modules indexed, postings refer to a module by its position in them
+	Modules []string `json:"modules"`
+	Titles  []string `json:"titles"`
+	// Terms maps each stemmed term to the modules containing it
+	Terms map[string][]SearchPosting `json:"terms"`
+}
+
+type SearchPosting struct {
+	Module int `json:"m"`
+	// Weight sums the weight of the fields the term appears in, once per occurrence
+	Weight int `json:"w"`
+}
+
+type SearchResult struct {
+	Code  string  `json:"code"`
+	Title string  `json:"title"`
+	Score float64 `json:"score"`
+}
+
+// Changelog lists what changed in a semester between the previously published snapshot and a new scrape
+type Changelog struct {
+	Semester    string `json:"semester"`
+	GeneratedAt string `json:"generatedAt"`
+	// Initial is set when nothing was published before, every module is then added
+	Initial bool           `json:"initial"`
+	Added   []ModuleChange `json:"added"`
+	Removed []ModuleChange `json:"removed"`
+	Changed []ModuleChange `json:"changed"`
+}
+
+// ModuleChange describes how a single module changed, only Code, Title and Faculty are set for added and removed modules
+type ModuleChange struct {
+	Code    string  `json:"code"`
+	Title   string  `json:"title"`
+	Faculty Faculty `json:"faculty"`
+	// Fields lists the changed course details, such as the title or the prerequisite
+	Fields         []FieldChange `json:"fields,omitempty"`
+	AddedIndexes   []string      `json:"addedIndexes,omitempty"`
+	RemovedIndexes []string      `json:"removedIndexes,omitempty"`
+	Classes        []ClassChange `json:"classes,omitempty"`
+	Exam           *ExamChange   `json:"exam,omitempty"`
+}
+
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// ClassChange is a class of an index that was added, removed or changed, Field is "class" for added and removed classes
+type ClassChange struct {
+	Index      string `json:"index"`
+	ClassType  string `json:"classType"`
+	IndexGroup string `json:"indexGroup"`
+	Field      string `json:"field"`
+	Before     string `json:"before"`
+	After      string `json:"after"`
+}
+
+// ExamChange is an exam that moved, Before or After is nil when the module gained or lost its exam
+type ExamChange struct {
+	Before *ExamSchedule `json:"before"`
+	After  *ExamSchedule `json:"after"`
+}
+
+// Webhook is a URL notified of the changes of each scrape, the filters left empty match every change
+type Webhook struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+	// Secret signs every payload, it is only returned when the webhook is registered
+	Secret    string   `json:"secret,omitempty"`
+	Codes     []string `json:"codes,omitempty"`
+	Faculties []string `json:"faculties,omitempty"`
+	// Events lists the WEBHOOK_* kinds of change to be notified of
+	Events    []string `json:"events,omitempty"`
+	CreatedAt string   `json:"createdAt"`
+}
+
+// WebhookPayload is the body POSTed to a webhook, its Changelog only holds the changes the webhook is filtered to
+type WebhookPayload struct {
+	DeliveryID string `json:"deliveryId"`
+	WebhookID  string `json:"webhookId"`
+	Changelog
+}
+
+// WebhookDeadLetter records a payload that could not be delivered after every attempt
+type WebhookDeadLetter struct {
+	URL       string         `json:"url"`
+	Attempts  int            `json:"attempts"`
+	LastError string         `json:"lastError"`
+	FailedAt  string         `json:"failedAt"`
+	Payload   WebhookPayload `json:"payload"`
+}
+
+// Eligibility is whether a student may take a module as each kind of elective
+type Eligibility struct {
+	Code       string      `json:"code"`
+	Title      string      `json:"title"`
+	AU         float32     `json:"au"`
+	Faculty    Faculty     `json:"faculty"`
+	UE         bool        `json:"ue"`
+	PE         bool        `json:"pe"`
+	BDE        bool        `json:"bde"`
+	Exclusions []Exclusion `json:"exclusions,omitempty"`
+}
+
+// Exclusion is a restriction that keeps a student from taking a module as the kinds of elective listed
+type Exclusion struct {
+	Kinds  []string `json:"kinds"`
+	Reason string   `json:"reason"`
+}
+
+// EligibilityResult splits the modules of a semester by whether a student may take them as Kind, any kind if empty
+type EligibilityResult struct {
+	Programme     string        `json:"programme"`
+	Year          int           `json:"year,omitempty"`
+	AdmissionYear int           `json:"admissionYear,omitempty"`
+	Kind          string        `json:"kind,omitempty"`
+	Eligible      []Eligibility `json:"eligible"`
+	Excluded      []Eligibility `json:"excluded"`
+}
+
+// FacultyMappingStatus describes the faculty mapping in use
+type FacultyMappingStatus struct {
+	// Source is the path or URL the mapping was read from, or "embedded"
+	Source string `json:"source"`
+	// Version is the start of the SHA-256 of the mapping, it changes whenever the mapping does
+	Version   string `json:"version"`
+	LoadedAt  string `json:"loadedAt"`
+	Faculties int    `json:"faculties"`
+	Prefixes  int    `json:"prefixes"`
+}
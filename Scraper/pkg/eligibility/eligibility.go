@@ -0,0 +1,210 @@
+package eligibility
+
+import (
+	"fmt"
+	"ntumods/pkg/dto"
+	"ntumods/pkg/parser"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Student is who eligibility is checked for
+type Student struct {
+	// Programme is the programme code, e.g. "CSC", a CourseYearProg such as "CSC;;1;F" is accepted as well
+	Programme string
+	// Year is the year of study, 0 if unknown, it is taken from Programme when that is a CourseYearProg
+	Year int
+	// AdmissionYear is the year the student was admitted, 0 if unknown
+	AdmissionYear int
+}
+
+// admissionYearPattern matches qualifiers such as "(Admyr 2011-2020)" and "(Admyr 2021-onwards)"
+var admissionYearPattern = regexp.MustCompile(`(?i)admyr\s*(\d{4})(?:\s*-\s*(\d{4}|onwards))?`)
+
+var allKinds = []string{dto.ELECTIVE_UE, dto.ELECTIVE_PE, dto.ELECTIVE_BDE}
+
+// ValidKind reports whether kind is one of the ELECTIVE_ kinds, or empty for any kind
+func ValidKind(kind string) bool {
+	return kind == "" || kind == dto.ELECTIVE_UE || kind == dto.ELECTIVE_PE || kind == dto.ELECTIVE_BDE
+}
+
+// Check works out which kinds of elective student may take course as, with the restriction behind every exclusion
+func Check(course dto.Course, student Student) dto.Eligibility {
+	result := dto.Eligibility{
+		Code:    course.Code,
+		Title:   course.Title,
+		AU:      course.AU,
+		Faculty: course.Faculty,
+		UE:      true,
+		PE:      true,
+		BDE:     true,
+	}
+
+	restrictions := []struct {
+		codes  []string
+		raw    string
+		kinds  []string
+		reason string
+	}{
+		{course.NotAvailableToCodes, course.NotAvailableTo, allKinds, "Not available to programme"},
+		{course.NotAvailableToProgWithCodes, course.NotAvailableToProgWith, allKinds, "Not available to all programmes with"},
+		{course.NotAvailableAsUECodes, course.NotAvailableAsUE, []string{dto.ELECTIVE_UE, dto.ELECTIVE_BDE}, "Not available as BDE/UE to programme"},
+		{course.NotAvailableAsPECodes, course.NotAvailableAsPE, []string{dto.ELECTIVE_PE}, "Not available as PE to programme"},
+	}
+
+	for _, restriction := range restrictions {
+		codes := restriction.codes
+		// Parsing the text again gives nothing for a module without this restriction
+		if codes == nil {
+			codes = parser.ParseProgrammeCodes(restriction.raw)
+		}
+
+		for _, code := range codes {
+			if matches(code, student) {
+				exclude(&result, restriction.kinds, fmt.Sprintf("%s %s", restriction.reason, code))
+			}
+		}
+	}
+
+	if course.NotOfferedAsBDE {
+		exclude(&result, []string{dto.ELECTIVE_BDE}, "Not offered as Broadening and Deepening Elective")
+	}
+
+	return result
+}
+
+// Filter checks every module for student, splitting them by whether they may be taken as kind, any kind if empty.
+// On top of the restrictions checked by Check, only the modules listed under the programme of student are prescribed
+// electives to them, and those modules are part of their curriculum that cannot be taken as UE or BDE. Both lists are
+// sorted by code.
+func Filter(modules []dto.Combined, student Student, kind string) dto.EligibilityResult {
+	result := dto.EligibilityResult{
+		Programme:     student.Programme,
+		Year:          student.Year,
+		AdmissionYear: student.AdmissionYear,
+		Kind:          kind,
+		Eligible:      []dto.Eligibility{},
+		Excluded:      []dto.Eligibility{},
+	}
+
+	for _, m := range modules {
+		e := Check(m.Course, student)
+		checkCurriculum(&e, m.Programmes, student)
+
+		eligible := false
+		switch kind {
+		case dto.ELECTIVE_UE:
+			eligible = e.UE
+		case dto.ELECTIVE_PE:
+			eligible = e.PE
+		case dto.ELECTIVE_BDE:
+			eligible = e.BDE
+		default:
+			eligible = e.UE || e.PE || e.BDE
+		}
+
+		if eligible {
+			result.Eligible = append(result.Eligible, e)
+		} else {
+			result.Excluded = append(result.Excluded, e)
+		}
+	}
+
+	for _, list := range [][]dto.Eligibility{result.Eligible, result.Excluded} {
+		sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	}
+
+	return result
+}
+
+// checkCurriculum excludes a module listed under the programme of student from UE and BDE, and any other module from
+// PE. WIS only shows whether a module is core or elective to some programmes, so every module a programme lists in any
+// year is taken to be part of its curriculum whatever its status.
+func checkCurriculum(e *dto.Eligibility, offerings []dto.ProgrammeOffering, student Student) {
+	programme, _ := programmeAndYear(student)
+
+	for _, o := range offerings {
+		if code, _ := programmeAndYear(Student{Programme: o.Programme}); strings.EqualFold(code, programme) {
+			exclude(e, []string{dto.ELECTIVE_UE, dto.ELECTIVE_BDE}, fmt.Sprintf("In the curriculum of programme %s", o.Programme))
+			return
+		}
+	}
+
+	exclude(e, []string{dto.ELECTIVE_PE}, fmt.Sprintf("Not listed under programme %s", programme))
+}
+
+// matches reports whether the restriction entry, e.g. "CSC", "CSC 1", "CSC(Admyr 2021-onwards)" or
+// "(Admyr 2011-2020)", applies to student. An entry limited to a year of study or admission years applies when
+// that year of student is unknown.
+func matches(entry string, student Student) bool {
+	code := entry
+	qualifier := ""
+	if i := strings.Index(entry, "("); i >= 0 {
+		code = strings.TrimSpace(entry[:i])
+		qualifier = entry[i:]
+	}
+
+	// "BCE 1" only restricts year 1 of BCE
+	year := 0
+	if fields := strings.Fields(code); len(fields) == 2 {
+		if y, err := strconv.Atoi(fields[1]); err == nil {
+			code, year = fields[0], y
+		}
+	}
+
+	programme, studentYear := programmeAndYear(student)
+	if code != "" && !strings.EqualFold(code, programme) {
+		return false
+	}
+	if year != 0 && studentYear != 0 && year != studentYear {
+		return false
+	}
+
+	match := admissionYearPattern.FindStringSubmatch(qualifier)
+	if match == nil {
+		// Anything but an admission year, e.g. a second major, cannot be checked and is taken to apply
+		return true
+	}
+	if student.AdmissionYear == 0 {
+		return true
+	}
+
+	from, _ := strconv.Atoi(match[1])
+	if student.AdmissionYear < from {
+		return false
+	}
+	if match[2] == "" {
+		return student.AdmissionYear == from
+	}
+	if strings.EqualFold(match[2], "onwards") {
+		return true
+	}
+	to, _ := strconv.Atoi(match[2])
+	return student.AdmissionYear <= to
+}
+
+// programmeAndYear splits the programme code and year of study of student out of a CourseYearProg
+func programmeAndYear(student Student) (string, int) {
+	fields := strings.Split(student.Programme, ";")
+	year := student.Year
+	if year == 0 && len(fields) > 2 {
+		year, _ = strconv.Atoi(strings.TrimSpace(fields[2]))
+	}
+	return strings.TrimSpace(fields[0]), year
+}
+
+func exclude(e *dto.Eligibility, kinds []string, reason string) {
+	for _, kind := range kinds {
+		switch kind {
+		case dto.ELECTIVE_UE:
+			e.UE = false
+		case dto.ELECTIVE_PE:
+			e.PE = false
+		case dto.ELECTIVE_BDE:
+			e.BDE = false
+		}
+	}
+	e.Exclusions = append(e.Exclusions, dto.Exclusion{Kinds: kinds, Reason: reason})
+}
@@ -0,0 +1,133 @@
+package eligibility
+
+import (
+	"ntumods/pkg/dto"
+	"ntumods/pkg/programme"
+	"reflect"
+	"testing"
+)
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		entry   string
+		student Student
+		matches bool
+	}{
+		{"CSC", Student{Programme: "CSC"}, true},
+		{"csc", Student{Programme: "CSC;;1;F"}, true},
+		{"CSC", Student{Programme: "CE"}, false},
+		// Year of study
+		{"CSC 1", Student{Programme: "CSC", Year: 1}, true},
+		{"CSC 1", Student{Programme: "CSC", Year: 2}, false},
+		{"CSC 1", Student{Programme: "CSC;;2;F"}, false},
+		{"CSC 1", Student{Programme: "CSC"}, true},
+		// A range of admission years includes both ends
+		{"CSC(Admyr 2011-2020)", Student{Programme: "CSC", AdmissionYear: 2010}, false},
+		{"CSC(Admyr 2011-2020)", Student{Programme: "CSC", AdmissionYear: 2011}, true},
+		{"CSC(Admyr 2011-2020)", Student{Programme: "CSC", AdmissionYear: 2015}, true},
+		{"CSC(Admyr 2011-2020)", Student{Programme: "CSC", AdmissionYear: 2020}, true},
+		{"CSC(Admyr 2011-2020)", Student{Programme: "CSC", AdmissionYear: 2021}, false},
+		{"CSC(Admyr 2011 - 2020)", Student{Programme: "CSC", AdmissionYear: 2021}, false},
+		{"CSC(Admyr 2011-2020)", Student{Programme: "CE", AdmissionYear: 2015}, false},
+		{"CSC(Admyr 2011-2020)", Student{Programme: "CSC"}, true},
+		// Open ended
+		{"CSC(Admyr 2021-onwards)", Student{Programme: "CSC", AdmissionYear: 2020}, false},
+		{"CSC(Admyr 2021-onwards)", Student{Programme: "CSC", AdmissionYear: 2021}, true},
+		{"CSC(ADMYR 2021-Onwards)", Student{Programme: "CSC", AdmissionYear: 2030}, true},
+		// A single admission year
+		{"CSC(Admyr 2021)", Student{Programme: "CSC", AdmissionYear: 2021}, true},
+		{"CSC(Admyr 2021)", Student{Programme: "CSC", AdmissionYear: 2022}, false},
+		// Every programme admitted in those years
+		{"(Admyr 2011-2020)", Student{Programme: "CE", AdmissionYear: 2020}, true},
+		{"(Admyr 2011-2020)", Student{Programme: "CE", AdmissionYear: 2021}, false},
+		// Year of study and admission year together
+		{"CSC 2(Admyr 2021-onwards)", Student{Programme: "CSC", Year: 2, AdmissionYear: 2022}, true},
+		{"CSC 2(Admyr 2021-onwards)", Student{Programme: "CSC", Year: 2, AdmissionYear: 2020}, false},
+		// Qualifiers that cannot be checked apply
+		{"CSC(2nd Major)", Student{Programme: "CSC", AdmissionYear: 2022}, true},
+	}
+
+	for _, tt := range tests {
+		if got := matches(tt.entry, tt.student); got != tt.matches {
+			t.Errorf("matches(%q, %+v) is %v, expected %v", tt.entry, tt.student, got, tt.matches)
+		}
+	}
+}
+
+func offeredModule(code string, courseYearProgs ...string) dto.Combined {
+	var m dto.Combined
+	m.Code = code
+	for _, courseYearProg := range courseYearProgs {
+		m.Programmes = append(m.Programmes, programme.NewOffering(courseYearProg, "", ""))
+	}
+	return m
+}
+
+func eligibleCodes(result dto.EligibilityResult) []string {
+	codes := []string{}
+	for _, e := range result.Eligible {
+		codes = append(codes, e.Code)
+	}
+	return codes
+}
+
+func TestFilter(t *testing.T) {
+	barredAsPE := offeredModule("AC2101", "ACC;GA;2;F")
+	barredAsPE.NotAvailableAsPECodes = []string{"ACC(Admyr 2021-onwards)"}
+
+	barredAsUE := offeredModule("CZ1003", "CSC;;1;F")
+	barredAsUE.NotAvailableAsUECodes = []string{"ACC"}
+
+	modules := []dto.Combined{
+		offeredModule("AB1201", "ACC;GA;1;F", "BUS;;1;F"),
+		offeredModule("AC1103", "ACC;GA;1;F"),
+		barredAsPE,
+		barredAsUE,
+		offeredModule("HE9091", "ECON;;2;F"),
+		offeredModule("ML0003"),
+	}
+
+	tests := []struct {
+		name     string
+		student  Student
+		kind     string
+		expected []string
+	}{
+		// Any module of the programme, in any year, less the ones barred to the student
+		{"pe", Student{Programme: "ACC", AdmissionYear: 2022}, dto.ELECTIVE_PE, []string{"AB1201", "AC1103"}},
+		{"pe admitted before the restriction", Student{Programme: "ACC;GA;1;F", AdmissionYear: 2020}, dto.ELECTIVE_PE, []string{"AB1201", "AC1103", "AC2101"}},
+		{"pe of another programme", Student{Programme: "BUS"}, dto.ELECTIVE_PE, []string{"AB1201"}},
+		// Modules outside the programme, less the ones barred as UE
+		{"ue", Student{Programme: "ACC", AdmissionYear: 2022}, dto.ELECTIVE_UE, []string{"HE9091", "ML0003"}},
+		{"ue of another programme", Student{Programme: "CSC"}, dto.ELECTIVE_UE, []string{"AB1201", "AC1103", "AC2101", "HE9091", "ML0003"}},
+		// Modules outside the programme as well, CZ1003 being barred as BDE along with UE
+		{"bde", Student{Programme: "ACC", AdmissionYear: 2022}, dto.ELECTIVE_BDE, []string{"HE9091", "ML0003"}},
+		{"bde of another programme", Student{Programme: "BUS"}, dto.ELECTIVE_BDE, []string{"AC1103", "AC2101", "CZ1003", "HE9091", "ML0003"}},
+		// CZ1003 is barred as UE and BDE, and is not a module of the programme to be taken as PE. AC2101 is in the
+		// curriculum, which rules out UE and BDE, and is barred as PE.
+		{"any", Student{Programme: "ACC", AdmissionYear: 2022}, "", []string{"AB1201", "AC1103", "HE9091", "ML0003"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := eligibleCodes(Filter(modules, tt.student, tt.kind)); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("eligible modules are %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFilterReasons(t *testing.T) {
+	modules := []dto.Combined{offeredModule("AC1103", "ACC;GA;1;F"), offeredModule("HE9091", "ECON;;2;F")}
+
+	result := Filter(modules, Student{Programme: "ACC"}, "")
+	expected := map[string][]dto.Exclusion{
+		"AC1103": {{Kinds: []string{dto.ELECTIVE_UE, dto.ELECTIVE_BDE}, Reason: "In the curriculum of programme ACC;GA;1;F"}},
+		"HE9091": {{Kinds: []string{dto.ELECTIVE_PE}, Reason: "Not listed under programme ACC"}},
+	}
+	for _, e := range result.Eligible {
+		if !reflect.DeepEqual(e.Exclusions, expected[e.Code]) {
+			t.Errorf("%s is excluded by %+v, expected %+v", e.Code, e.Exclusions, expected[e.Code])
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package faculty
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"ntumods/data"
+	"ntumods/pkg/dto"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// embeddedSource is the Status().Source of the mapping embedded in the binary
+const embeddedSource = "embedded"
+
+// Loader keeps the faculty mapping in use, read from its source and swapped only once it passes validation
+type Loader struct {
+	source string
+	client *http.Client
+
+	mu      sync.RWMutex
+	mapping map[string]dto.Faculty
+	status  dto.FacultyMappingStatus
+}
+
+// NewLoader reads the mapping from source, a file path or an http(s) URL, or the mapping embedded in the binary
+// when source is empty. It starts out with the embedded mapping, which stays in use until Reload reads source.
+func NewLoader(source string) *Loader {
+	l := &Loader{
+		source: source,
+		client: &http.Client{Timeout: dto.REQUEST_TIMEOUT},
+	}
+
+	if _, err := l.load(data.Faculty, embeddedSource); err != nil {
+		fmt.Println("[NewLoader] The embedded faculty mapping is invalid:", err)
+	}
+	return l
+}
+
+// Reload reads the mapping from the source again, keeping the current mapping if the new one cannot be read or is
+// invalid
+func (l *Loader) Reload() (dto.FacultyMappingStatus, error) {
+	raw, err := l.read()
+	if err != nil {
+		return dto.FacultyMappingStatus{}, fmt.Errorf("[Loader.Reload] Failed to read faculty mapping from %s: %v", l.describeSource(), err)
+	}
+
+	return l.load(raw, l.describeSource())
+}
+
+// load swaps in the mapping in raw if it is valid
+func (l *Loader) load(raw []byte, source string) (dto.FacultyMappingStatus, error) {
+	mapping, resolver, err := parse(raw)
+	if err != nil {
+		return dto.FacultyMappingStatus{}, err
+	}
+
+	sum := sha256.Sum256(raw)
+	faculties := make(map[string]bool)
+	for _, faculty := range mapping {
+		faculties[faculty.Code] = true
+	}
+
+	status := dto.FacultyMappingStatus{
+		Source:    source,
+		Version:   hex.EncodeToString(sum[:])[:12],
+		LoadedAt:  time.Now().Format(time.RFC3339),
+		Faculties: len(faculties),
+		Prefixes:  len(resolver.prefixes),
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.mapping = mapping
+	l.status = status
+
+	return status, nil
+}
+
+// Status describes the mapping in use
+func (l *Loader) Status() dto.FacultyMappingStatus {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.status
+}
+
+// Resolver returns a new Resolver over the mapping in use, so that each scrape reports its own unmatched codes
+func (l *Loader) Resolver() *Resolver {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	// The mapping was validated when it was loaded
+	resolver, _ := newResolver(l.mapping)
+	return resolver
+}
+
+func (l *Loader) read() ([]byte, error) {
+	switch {
+	case l.source == "":
+		return data.Faculty, nil
+	case strings.HasPrefix(l.source, "http://") || strings.HasPrefix(l.source, "https://"):
+		req, err := http.NewRequest(http.MethodGet, l.source, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("User-Agent", dto.USER_AGENT)
+
+		resp, err := l.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, dto.FACULTY_MAPPING_MAX_SIZE))
+			return nil, fmt.Errorf("status code %d", resp.StatusCode)
+		}
+
+		// Read one byte past the limit to tell a mapping of exactly the limit from a larger one
+		raw, err := io.ReadAll(io.LimitReader(resp.Body, dto.FACULTY_MAPPING_MAX_SIZE+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(raw) > dto.FACULTY_MAPPING_MAX_SIZE {
+			return nil, fmt.Errorf("mapping is larger than %d bytes", dto.FACULTY_MAPPING_MAX_SIZE)
+		}
+		return raw, nil
+	default:
+		return os.ReadFile(l.source)
+	}
+}
+
+func (l *Loader) describeSource() string {
+	if l.source == "" {
+		return embeddedSource
+	}
+	return l.source
+}
@@ -0,0 +1,151 @@
+package faculty
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"ntumods/pkg/dto"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testMapping = `{"AB;AC": {"Faculty": "Nanyang Business School", "Code": "NBS"}, "ZZ": {"Faculty": "Test School", "Code": "TEST"}}`
+
+// mappingServer serves whatever mapping it was last given with the status given
+type mappingServer struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	status int
+	body   string
+}
+
+func newMappingServer(t *testing.T, body string) *mappingServer {
+	s := &mappingServer{status: http.StatusOK, body: body}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		w.WriteHeader(s.status)
+		w.Write([]byte(s.body))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *mappingServer) Serve(status int, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+	s.body = body
+}
+
+func resolvesTo(t *testing.T, loader *Loader, code string) string {
+	faculty, _ := loader.Resolver().Resolve(code)
+	return faculty.Code
+}
+
+func TestLoaderURL(t *testing.T) {
+	server := newMappingServer(t, testMapping)
+	loader := NewLoader(server.URL)
+
+	// The embedded mapping is in use until the source is read
+	if source := loader.Status().Source; source != "embedded" {
+		t.Errorf("source is %q before reloading, expected embedded", source)
+	}
+	if code := resolvesTo(t, loader, "HE1001"); code != "SSS" {
+		t.Errorf("HE1001 resolves to %q before reloading, expected SSS", code)
+	}
+
+	status, err := loader.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Source != server.URL || status.Faculties != 2 || status.Prefixes != 3 || status.Version == "" {
+		t.Errorf("status is %+v", status)
+	}
+	if loader.Status() != status {
+		t.Errorf("Status is %+v, expected %+v", loader.Status(), status)
+	}
+	if code := resolvesTo(t, loader, "ZZ1001"); code != "TEST" {
+		t.Errorf("ZZ1001 resolves to %q, expected TEST", code)
+	}
+
+	// An invalid mapping or a failed request keeps the mapping in use
+	failures := []struct {
+		name    string
+		status  int
+		body    string
+		invalid bool
+	}{
+		{"duplicate key", http.StatusOK, `{"ZZ": {"Faculty": "Test School", "Code": "TEST"}, "ZZ": {"Faculty": "Other", "Code": "OTHER"}}`, true},
+		{"not json", http.StatusOK, `<html>`, true},
+		{"server error", http.StatusInternalServerError, testMapping, false},
+		{"too large", http.StatusOK, strings.Repeat(" ", dto.FACULTY_MAPPING_MAX_SIZE+1), false},
+	}
+	for _, tt := range failures {
+		t.Run(tt.name, func(t *testing.T) {
+			server.Serve(tt.status, tt.body)
+
+			_, err := loader.Reload()
+			var validation *ValidationError
+			if err == nil || errors.As(err, &validation) != tt.invalid {
+				t.Errorf("Reload returned %v, expected a validation error: %v", err, tt.invalid)
+			}
+			if loader.Status() != status {
+				t.Errorf("Status is %+v after a failed reload, expected %+v", loader.Status(), status)
+			}
+			if code := resolvesTo(t, loader, "ZZ1001"); code != "TEST" {
+				t.Errorf("ZZ1001 resolves to %q after a failed reload, expected TEST", code)
+			}
+		})
+	}
+}
+
+func TestLoaderUnreachableURL(t *testing.T) {
+	server := newMappingServer(t, testMapping)
+	server.Close()
+
+	loader := NewLoader(server.URL)
+	if _, err := loader.Reload(); err == nil {
+		t.Fatal("Reload succeeded although the URL is unreachable")
+	}
+
+	if source := loader.Status().Source; source != "embedded" {
+		t.Errorf("source is %q, expected the embedded mapping to be in use", source)
+	}
+	if code := resolvesTo(t, loader, "AB1201"); code != "NBS" {
+		t.Errorf("AB1201 resolves to %q, expected NBS from the embedded mapping", code)
+	}
+}
+
+func TestLoaderFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "faculty.json")
+	loader := NewLoader(path)
+
+	if _, err := loader.Reload(); err == nil {
+		t.Error("Reload succeeded although the file is missing")
+	}
+
+	if err := os.WriteFile(path, []byte(`{"AB;;AC": {"Faculty": "", "Code": "NBS"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := loader.Reload()
+	var validation *ValidationError
+	if !errors.As(err, &validation) || len(validation.Problems) != 2 {
+		t.Errorf("Reload returned %v, expected an empty name and an empty prefix", err)
+	}
+
+	if err = os.WriteFile(path, []byte(testMapping), 0644); err != nil {
+		t.Fatal(err)
+	}
+	status, err := loader.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Source != path {
+		t.Errorf("source is %q, expected %q", status.Source, path)
+	}
+}
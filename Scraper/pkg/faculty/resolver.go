@@ -0,0 +1,128 @@
+package faculty
+
+import (
+	"fmt"
+	"ntumods/pkg/dto"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Resolver finds the faculty of a module from the longest prefix of its code that a faculty is mapped to,
+// e.g. "AAB" before "AA". It is safe for concurrent use.
+type Resolver struct {
+	prefixes map[string]dto.Faculty
+	longest  int
+
+	mu        sync.Mutex
+	unmatched map[string]bool
+}
+
+// Parse reads a mapping in the format of data/faculty.json, where each key lists the module code prefixes of a
+// faculty separated by semicolons. Besides the ambiguous prefixes rejected by NewResolver, keys that appear more
+// than once, faculties without a code or name and empty or repeated prefixes are reported as a *ValidationError.
+func Parse(data []byte) (*Resolver, error) {
+	_, resolver, err := parse(data)
+	return resolver, err
+}
+
+func parse(data []byte) (map[string]dto.Faculty, *Resolver, error) {
+	mapping, problems, err := decode(data)
+	if err != nil {
+		return nil, nil, &ValidationError{Problems: []string{err.Error()}}
+	}
+
+	r, ambiguous := newResolver(mapping)
+	if problems = append(problems, ambiguous...); len(problems) > 0 {
+		return nil, nil, &ValidationError{Problems: problems}
+	}
+	return mapping, r, nil
+}
+
+// NewResolver builds a Resolver from mapping, keyed by semicolon separated prefixes. A prefix mapped to more than
+// one faculty is ambiguous and rejected, the error lists every such prefix.
+func NewResolver(mapping map[string]dto.Faculty) (*Resolver, error) {
+	r, ambiguous := newResolver(mapping)
+	if len(ambiguous) > 0 {
+		return nil, fmt.Errorf("[faculty.NewResolver] %s", strings.Join(ambiguous, "; "))
+	}
+	return r, nil
+}
+
+func newResolver(mapping map[string]dto.Faculty) (*Resolver, []string) {
+	r := &Resolver{
+		prefixes:  make(map[string]dto.Faculty),
+		unmatched: make(map[string]bool),
+	}
+
+	owners := make(map[string]map[string]bool)
+	for keys, faculty := range mapping {
+		for _, prefix := range strings.Split(keys, ";") {
+			prefix = strings.ToUpper(strings.TrimSpace(prefix))
+			if prefix == "" {
+				continue
+			}
+
+			if owners[prefix] == nil {
+				owners[prefix] = make(map[string]bool)
+			}
+			owners[prefix][faculty.Code] = true
+
+			r.prefixes[prefix] = faculty
+			if len(prefix) > r.longest {
+				r.longest = len(prefix)
+			}
+		}
+	}
+
+	var ambiguous []string
+	for prefix, faculties := range owners {
+		if len(faculties) > 1 {
+			var codes []string
+			for code := range faculties {
+				codes = append(codes, code)
+			}
+			sort.Strings(codes)
+			ambiguous = append(ambiguous, fmt.Sprintf("prefix %s is mapped to more than one faculty: %s", prefix, strings.Join(codes, ", ")))
+		}
+	}
+	sort.Strings(ambiguous)
+
+	return r, ambiguous
+}
+
+// Resolve returns the faculty mapped to the longest prefix of code, false if there is none. Codes without a
+// faculty are remembered for Unmatched.
+func (r *Resolver) Resolve(code string) (dto.Faculty, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+
+	n := r.longest
+	if len(code) < n {
+		n = len(code)
+	}
+	for ; n > 0; n-- {
+		if faculty, exists := r.prefixes[code[:n]]; exists {
+			return faculty, true
+		}
+	}
+
+	if code != "" {
+		r.mu.Lock()
+		r.unmatched[code] = true
+		r.mu.Unlock()
+	}
+	return dto.Faculty{}, false
+}
+
+// Unmatched returns every module code that Resolve found no faculty for, sorted
+func (r *Resolver) Unmatched() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	codes := make([]string, 0, len(r.unmatched))
+	for code := range r.unmatched {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
@@ -0,0 +1,189 @@
+package faculty
+
+import (
+	"errors"
+	"ntumods/data"
+	"ntumods/pkg/dto"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var (
+	nbs = dto.Faculty{Title: "Nanyang Business School", Code: "NBS"}
+	nie = dto.Faculty{Title: "National Institute of Education", Code: "NIE"}
+	sss = dto.Faculty{Title: "School of Social Sciences", Code: "SSS"}
+)
+
+func TestResolve(t *testing.T) {
+	resolver, err := NewResolver(map[string]dto.Faculty{
+		"AB;AC":   nbs,
+		"AAB;AAA": nie,
+		"A":       sss,
+		"HE":      sss,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		code     string
+		expected dto.Faculty
+		matched  bool
+	}{
+		{"AB1201", nbs, true},
+		{"AC1103", nbs, true},
+		// The longest prefix wins
+		{"AAB101", nie, true},
+		{"AAC101", sss, true},
+		{"AD1101", sss, true},
+		{"HE1001", sss, true},
+		// Any case and surrounding space
+		{" aab101 ", nie, true},
+		// Codes shorter than the longest prefix
+		{"AA", sss, true},
+		{"A", sss, true},
+		{"H", dto.Faculty{}, false},
+		{"", dto.Faculty{}, false},
+		{"ZZ1001", dto.Faculty{}, false},
+	}
+
+	for _, tt := range tests {
+		faculty, matched := resolver.Resolve(tt.code)
+		if faculty != tt.expected || matched != tt.matched {
+			t.Errorf("Resolve(%q) is %+v, %v, expected %+v, %v", tt.code, faculty, matched, tt.expected, tt.matched)
+		}
+	}
+
+	// Each code is reported once, in upper case, and the empty code not at all
+	resolver.Resolve("zz1001")
+	if unmatched, expected := resolver.Unmatched(), []string{"H", "ZZ1001"}; !reflect.DeepEqual(unmatched, expected) {
+		t.Errorf("Unmatched is %v, expected %v", unmatched, expected)
+	}
+}
+
+func TestUnmatchedEmpty(t *testing.T) {
+	resolver, err := NewResolver(map[string]dto.Faculty{"AB": nbs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolver.Resolve("AB1201")
+
+	if unmatched := resolver.Unmatched(); unmatched == nil || len(unmatched) != 0 {
+		t.Errorf("Unmatched is %#v, expected an empty list", unmatched)
+	}
+}
+
+func TestNewResolverAmbiguous(t *testing.T) {
+	_, err := NewResolver(map[string]dto.Faculty{
+		"AB;HE": nbs,
+		"he;HA": sss,
+		"AA":    nie,
+	})
+	if err == nil || !strings.Contains(err.Error(), "prefix HE is mapped to more than one faculty: NBS, SSS") {
+		t.Errorf("NewResolver returned %v, expected HE to be ambiguous", err)
+	}
+
+	// The same prefix listed under two keys of one faculty is not ambiguous
+	if _, err = NewResolver(map[string]dto.Faculty{"AB": nbs, "AB;AC": nbs}); err != nil {
+		t.Errorf("NewResolver returned %v", err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		problems []string
+	}{
+		{
+			"valid",
+			`{"AB;AC": {"Faculty": "Nanyang Business School", "Code": "NBS"}, "AAB": {"Faculty": "National Institute of Education", "Code": "NIE"}}`,
+			nil,
+		},
+		{
+			"duplicate key",
+			`{"AB": {"Faculty": "Nanyang Business School", "Code": "NBS"}, "AB": {"Faculty": "School of Social Sciences", "Code": "SSS"}}`,
+			[]string{`key "AB" appears more than once`},
+		},
+		{
+			"empty code and name",
+			`{"AB": {"Faculty": "", "Code": " "}}`,
+			[]string{`key "AB" has an empty faculty code`, `key "AB" has an empty faculty name`},
+		},
+		{
+			"empty prefix",
+			`{"AB;;AC": {"Faculty": "Nanyang Business School", "Code": "NBS"}}`,
+			[]string{`key "AB;;AC" lists an empty prefix`},
+		},
+		{
+			"repeated prefix",
+			`{"AB;AC": {"Faculty": "Nanyang Business School", "Code": "NBS"}, "ac": {"Faculty": "Nanyang Business School", "Code": "NBS"}}`,
+			[]string{"prefix AC is listed more than once for NBS"},
+		},
+		{
+			"ambiguous prefix",
+			`{"AB;HE": {"Faculty": "Nanyang Business School", "Code": "NBS"}, "HE": {"Faculty": "School of Social Sciences", "Code": "SSS"}}`,
+			[]string{"prefix HE is mapped to more than one faculty: NBS, SSS"},
+		},
+		{
+			"not an object",
+			`[]`,
+			[]string{"expected an object mapping prefixes to faculties"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver, err := Parse([]byte(tt.data))
+
+			if tt.problems == nil {
+				if err != nil || resolver == nil {
+					t.Errorf("Parse returned %v", err)
+				}
+				return
+			}
+
+			var validation *ValidationError
+			if !errors.As(err, &validation) {
+				t.Fatalf("Parse returned %v, expected a *ValidationError", err)
+			}
+			if !reflect.DeepEqual(validation.Problems, tt.problems) {
+				t.Errorf("problems are %q, expected %q", validation.Problems, tt.problems)
+			}
+			if resolver != nil {
+				t.Error("Parse returned a resolver for an invalid mapping")
+			}
+		})
+	}
+}
+
+// TestEmbeddedMapping checks that data/faculty.json is valid and pins the prefixes it used to list under two faculties
+func TestEmbeddedMapping(t *testing.T) {
+	mapping, _, err := parse(data.Faculty)
+	if err != nil {
+		t.Fatalf("the embedded faculty mapping is invalid: %v", err)
+	}
+	resolver, _ := newResolver(mapping)
+
+	tests := []struct {
+		code     string
+		expected string
+	}{
+		// Economics is taught by the School of Social Sciences, not Nanyang Business School
+		{"HE1001", "SSS"},
+		// Bioengineering moved to the School of Chemistry, Chemical Engineering and Biotechnology, it is not a
+		// School of Biological Sciences programme
+		{"BG1131", "CCEB"},
+		// AAB is one of the AA* subject prefixes of the National Institute of Education
+		{"AAB10A", "NIE"},
+		{"BS1001", "SBS"},
+		{"AB1201", "NBS"},
+	}
+
+	for _, tt := range tests {
+		if faculty, _ := resolver.Resolve(tt.code); faculty.Code != tt.expected {
+			t.Errorf("%s resolves to %q, expected %q", tt.code, faculty.Code, tt.expected)
+		}
+	}
+}
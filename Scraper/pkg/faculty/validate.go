@@ -0,0 +1,83 @@
+package faculty
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"ntumods/pkg/dto"
+	"strings"
+)
+
+// ValidationError lists every problem found in a faculty mapping
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid faculty mapping: %s", strings.Join(e.Problems, "; "))
+}
+
+// decode reads mapping key by key, as decoding into a map would silently keep only the last of duplicate keys
+func decode(data []byte) (map[string]dto.Faculty, []string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, nil, fmt.Errorf("expected an object mapping prefixes to faculties")
+	}
+
+	mapping := make(map[string]dto.Faculty)
+	var problems []string
+	// listedFor maps each prefix seen so far to the code of its faculty
+	listedFor := make(map[string]string)
+
+	for decoder.More() {
+		token, err = decoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		key := token.(string)
+
+		var faculty dto.Faculty
+		if err = decoder.Decode(&faculty); err != nil {
+			return nil, nil, fmt.Errorf("key %q: %v", key, err)
+		}
+
+		if _, exists := mapping[key]; exists {
+			// Its prefixes were checked the first time round
+			problems = append(problems, fmt.Sprintf("key %q appears more than once", key))
+			continue
+		}
+		if strings.TrimSpace(faculty.Code) == "" {
+			problems = append(problems, fmt.Sprintf("key %q has an empty faculty code", key))
+		}
+		if strings.TrimSpace(faculty.Title) == "" {
+			problems = append(problems, fmt.Sprintf("key %q has an empty faculty name", key))
+		}
+
+		for _, prefix := range strings.Split(key, ";") {
+			prefix = strings.ToUpper(strings.TrimSpace(prefix))
+			if prefix == "" {
+				problems = append(problems, fmt.Sprintf("key %q lists an empty prefix", key))
+				continue
+			}
+
+			// A prefix listed for different faculties is reported as ambiguous instead
+			if code, exists := listedFor[prefix]; exists && code == faculty.Code {
+				problems = append(problems, fmt.Sprintf("prefix %s is listed more than once for %s", prefix, faculty.Code))
+			}
+			listedFor[prefix] = faculty.Code
+		}
+
+		mapping[key] = faculty
+	}
+
+	if _, err = decoder.Token(); err != nil {
+		return nil, nil, err
+	}
+
+	return mapping, problems, nil
+}
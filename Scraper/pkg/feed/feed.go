@@ -0,0 +1,170 @@
+package feed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"ntumods/pkg/dto"
+	"sort"
+	"strings"
+	"time"
+)
+
+const contentType = "application/atom+xml"
+
+// Entries kept in a feed, the oldest are dropped first
+const maxEntries = 100
+
+func init() {
+	// Storages set the content type from the extension, which minimal images may not know
+	mime.AddExtensionType(".atom", contentType)
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	ID       string       `xml:"id"`
+	Title    string       `xml:"title"`
+	Updated  string       `xml:"updated"`
+	Category atomCategory `xml:"category"`
+	Content  atomContent  `xml:"content"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+// Write writes an Atom feed of the module changes in changelogs, newest first. A non-empty faculty limits the
+// feed to the modules of that faculty code. Changelogs of a first scrape are left out, as they add every module.
+// generatedAt is the time of the run writing the feed, which an empty feed is updated at.
+func Write(w io.Writer, semester string, faculty string, changelogs []dto.Changelog, generatedAt time.Time) error {
+	feed := atomFeed{
+		ID:      "urn:ntumods:" + semester + ":changes",
+		Title:   "NTU module changes in " + semester,
+		Author:  atomAuthor{Name: "ntumods"},
+		Entries: []atomEntry{},
+	}
+	if faculty != "" {
+		feed.ID += ":" + faculty
+		feed.Title += " (" + faculty + ")"
+	}
+
+	sorted := append([]dto.Changelog(nil), changelogs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].GeneratedAt > sorted[j].GeneratedAt })
+
+	for _, changelog := range sorted {
+		if changelog.Initial {
+			continue
+		}
+
+		updated, err := time.Parse(time.RFC3339, changelog.GeneratedAt)
+		if err != nil {
+			return fmt.Errorf("[feed.Write] Changelog has an invalid time %q: %v", changelog.GeneratedAt, err)
+		}
+
+		kinds := []struct {
+			kind    string
+			changes []dto.ModuleChange
+		}{
+			{dto.WEBHOOK_ADDED, changelog.Added},
+			{dto.WEBHOOK_REMOVED, changelog.Removed},
+			{"changed", changelog.Changed},
+		}
+		for _, k := range kinds {
+			for _, change := range k.changes {
+				if faculty != "" && !strings.EqualFold(change.Faculty.Code, faculty) {
+					continue
+				}
+
+				feed.Entries = append(feed.Entries, atomEntry{
+					ID:       fmt.Sprintf("urn:ntumods:%s:%s:%s", semester, change.Code, updated.UTC().Format("20060102T150405Z")),
+					Title:    fmt.Sprintf("%s %s %s", change.Code, change.Title, k.kind),
+					Updated:  updated.UTC().Format(time.RFC3339),
+					Category: atomCategory{Term: k.kind},
+					Content:  atomContent{Type: "text", Text: Describe(change, k.kind)},
+				})
+			}
+		}
+	}
+
+	if len(feed.Entries) > maxEntries {
+		feed.Entries = feed.Entries[:maxEntries]
+	}
+
+	feed.Updated = generatedAt.UTC().Format(time.RFC3339)
+	if len(feed.Entries) > 0 {
+		feed.Updated = feed.Entries[0].Updated
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	return encoder.Encode(feed)
+}
+
+// Describe lists what changed in a module, one change per line
+func Describe(change dto.ModuleChange, kind string) string {
+	switch kind {
+	case dto.WEBHOOK_ADDED:
+		return change.Code + " is now offered"
+	case dto.WEBHOOK_REMOVED:
+		return change.Code + " is no longer offered"
+	}
+
+	var lines []string
+	for _, f := range change.Fields {
+		lines = append(lines, fmt.Sprintf("%s changed from %q to %q", f.Field, f.Before, f.After))
+	}
+	if len(change.AddedIndexes) > 0 {
+		lines = append(lines, "New indexes: "+strings.Join(change.AddedIndexes, ", "))
+	}
+	if len(change.RemovedIndexes) > 0 {
+		lines = append(lines, "Indexes no longer offered: "+strings.Join(change.RemovedIndexes, ", "))
+	}
+	for _, c := range change.Classes {
+		class := fmt.Sprintf("Index %s %s %s", c.Index, c.ClassType, c.IndexGroup)
+		switch {
+		case c.Field == "class" && c.Before == "":
+			lines = append(lines, fmt.Sprintf("%s added on %s", class, c.After))
+		case c.Field == "class":
+			lines = append(lines, fmt.Sprintf("%s on %s removed", class, c.Before))
+		default:
+			lines = append(lines, fmt.Sprintf("%s %s changed from %s to %s", class, c.Field, c.Before, c.After))
+		}
+	}
+	if change.Exam != nil {
+		switch {
+		case change.Exam.Before == nil:
+			lines = append(lines, "Exam added on "+describeExam(*change.Exam.After))
+		case change.Exam.After == nil:
+			lines = append(lines, "Exam on "+describeExam(*change.Exam.Before)+" removed")
+		default:
+			lines = append(lines, "Exam moved from "+describeExam(*change.Exam.Before)+" to "+describeExam(*change.Exam.After))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func describeExam(exam dto.ExamSchedule) string {
+	return fmt.Sprintf("%s %s (%s)", exam.Date, exam.Time, exam.Duration)
+}
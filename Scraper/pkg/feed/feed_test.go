@@ -0,0 +1,135 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"ntumods/pkg/dto"
+	"testing"
+	"time"
+)
+
+var generatedAt = time.Date(2023, 8, 20, 10, 0, 0, 0, time.UTC)
+
+func change(code string, faculty string) dto.ModuleChange {
+	return dto.ModuleChange{
+		Code:    code,
+		Title:   "FINANCIAL MANAGEMENT",
+		Faculty: dto.Faculty{Code: faculty},
+		Fields:  []dto.FieldChange{{Field: "title", Before: "CORPORATE FINANCE", After: "FINANCIAL MANAGEMENT"}},
+	}
+}
+
+// write returns the feed Write wrote, checking that it is valid XML
+func write(t *testing.T, faculty string, changelogs []dto.Changelog) atomFeed {
+	var b bytes.Buffer
+	if err := Write(&b, "2023_1", faculty, changelogs, generatedAt); err != nil {
+		t.Fatal(err)
+	}
+
+	var feed atomFeed
+	if err := xml.Unmarshal(b.Bytes(), &feed); err != nil {
+		t.Fatalf("the feed is not valid XML: %v\n%s", err, b.String())
+	}
+	return feed
+}
+
+func titles(feed atomFeed) []string {
+	list := []string{}
+	for _, entry := range feed.Entries {
+		list = append(list, entry.Title)
+	}
+	return list
+}
+
+func TestWriteEmpty(t *testing.T) {
+	tests := []struct {
+		name       string
+		changelogs []dto.Changelog
+	}{
+		{"no changelogs", nil},
+		{"first scrape", []dto.Changelog{{Initial: true, GeneratedAt: "2023-08-14T10:00:00Z", Added: []dto.ModuleChange{change("AB1201", "NBS")}}}},
+		{"nothing changed", []dto.Changelog{{GeneratedAt: "2023-08-14T10:00:00Z"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feed := write(t, "", tt.changelogs)
+			if len(feed.Entries) != 0 {
+				t.Errorf("entries are %v, expected none", titles(feed))
+			}
+			if feed.Updated != "2023-08-20T10:00:00Z" {
+				t.Errorf("updated is %s, expected the time of the run", feed.Updated)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	changelogs := []dto.Changelog{
+		{GeneratedAt: "2023-08-14T10:00:00Z", Changed: []dto.ModuleChange{change("AB1201", "NBS")}},
+		{
+			GeneratedAt: "2023-08-15T18:00:00+08:00",
+			Added:       []dto.ModuleChange{change("CZ2001", "SCSE")},
+			Removed:     []dto.ModuleChange{change("AC1103", "NBS")},
+		},
+	}
+
+	feed := write(t, "", changelogs)
+
+	expected := []string{"CZ2001 FINANCIAL MANAGEMENT added", "AC1103 FINANCIAL MANAGEMENT removed", "AB1201 FINANCIAL MANAGEMENT changed"}
+	if got := titles(feed); fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("entries are %v, expected %v", got, expected)
+	}
+	if feed.Updated != "2023-08-15T10:00:00Z" {
+		t.Errorf("updated is %s, expected the time of the newest entry", feed.Updated)
+	}
+	if feed.Entries[0].ID != "urn:ntumods:2023_1:CZ2001:20230815T100000Z" || feed.Entries[0].Category.Term != dto.WEBHOOK_ADDED {
+		t.Errorf("the newest entry is %+v", feed.Entries[0])
+	}
+}
+
+func TestWriteFaculty(t *testing.T) {
+	changelogs := []dto.Changelog{{
+		GeneratedAt: "2023-08-14T10:00:00Z",
+		Added:       []dto.ModuleChange{change("CZ2001", "SCSE")},
+		Changed:     []dto.ModuleChange{change("AB1201", "NBS"), change("AC1104", "nbs")},
+	}}
+
+	feed := write(t, "NBS", changelogs)
+
+	expected := []string{"AB1201 FINANCIAL MANAGEMENT changed", "AC1104 FINANCIAL MANAGEMENT changed"}
+	if got := titles(feed); fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("entries are %v, expected %v", got, expected)
+	}
+	if feed.ID != "urn:ntumods:2023_1:changes:NBS" || feed.Title != "NTU module changes in 2023_1 (NBS)" {
+		t.Errorf("the feed is %s, %q", feed.ID, feed.Title)
+	}
+
+	if feed = write(t, "EEE", changelogs); len(feed.Entries) != 0 || feed.Updated != "2023-08-20T10:00:00Z" {
+		t.Errorf("the feed of a faculty without changes has entries %v and is updated %s", titles(feed), feed.Updated)
+	}
+}
+
+func TestWriteMaxEntries(t *testing.T) {
+	// Each run changes more modules than the feed holds, only the newest run is kept
+	var changelogs []dto.Changelog
+	for day := 14; day <= 15; day++ {
+		changelog := dto.Changelog{GeneratedAt: fmt.Sprintf("2023-08-%dT10:00:00Z", day)}
+		for i := 0; i < maxEntries; i++ {
+			changelog.Changed = append(changelog.Changed, change(fmt.Sprintf("AB%04d", i), "NBS"))
+		}
+		changelogs = append(changelogs, changelog)
+	}
+
+	feed := write(t, "", changelogs)
+
+	if len(feed.Entries) != maxEntries {
+		t.Fatalf("%d entries, expected %d", len(feed.Entries), maxEntries)
+	}
+	for _, entry := range feed.Entries {
+		if entry.Updated != "2023-08-15T10:00:00Z" {
+			t.Fatalf("entry %s of %s is kept, expected only the newest run", entry.Title, entry.Updated)
+		}
+	}
+}
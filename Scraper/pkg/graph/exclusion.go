@@ -0,0 +1,121 @@
+package graph
+
+import (
+	"ntumods/pkg/dto"
+	"ntumods/pkg/parser"
+	"sort"
+)
+
+// Exclusions is the mutually exclusive module graph, undirected as taking either module rules out the other
+type Exclusions struct {
+	titles    map[string]string
+	declared  map[string]map[string]bool
+	exclusive map[string]map[string]bool
+}
+
+func BuildExclusions(courses []dto.Course) *Exclusions {
+	e := &Exclusions{
+		titles:    make(map[string]string),
+		declared:  make(map[string]map[string]bool),
+		exclusive: make(map[string]map[string]bool),
+	}
+
+	for _, c := range courses {
+		if c.Code == "" {
+			continue
+		}
+		e.titles[c.Code] = c.Title
+	}
+
+	for _, c := range courses {
+		if c.Code == "" {
+			continue
+		}
+
+		codes := c.MutuallyExclusiveCodes
+		if codes == nil {
+			codes = parser.ParseMutuallyExclusive(c.MutuallyExclusive)
+		}
+
+		for _, code := range codes {
+			if code == c.Code {
+				continue
+			}
+			addEdge(e.declared, c.Code, code)
+			addEdge(e.exclusive, c.Code, code)
+			addEdge(e.exclusive, code, c.Code)
+		}
+	}
+
+	return e
+}
+
+func addEdge(edges map[string]map[string]bool, from string, to string) {
+	if edges[from] == nil {
+		edges[from] = make(map[string]bool)
+	}
+	edges[from][to] = true
+}
+
+// Exclusive returns the modules code is mutually exclusive with, sorted
+func (e *Exclusions) Exclusive(code string) []string {
+	return sortedSet(e.exclusive[code])
+}
+
+// OneSided returns the declarations not made in return by the other module, leaving out modules that were not
+// scraped as there is no telling what they declare. Sorted by module and then by the module excluded.
+func (e *Exclusions) OneSided() []dto.OneSidedExclusion {
+	oneSided := make([]dto.OneSidedExclusion, 0)
+	for _, code := range sortedKeysOf(e.declared) {
+		for _, other := range sortedSet(e.declared[code]) {
+			if _, scraped := e.titles[other]; scraped && !e.declared[other][code] {
+				oneSided = append(oneSided, dto.OneSidedExclusion{Module: code, Excludes: other})
+			}
+		}
+	}
+	return oneSided
+}
+
+// Missing maps module codes that are declared exclusive but were not scraped to the modules declaring them
+func (e *Exclusions) Missing() map[string][]string {
+	missing := make(map[string][]string)
+	for code, others := range e.exclusive {
+		if _, scraped := e.titles[code]; !scraped {
+			missing[code] = sortedSet(others)
+		}
+	}
+	return missing
+}
+
+// Adjacency returns the graph in the form published as exclusionGraph.json
+func (e *Exclusions) Adjacency() dto.ExclusionGraph {
+	adjacency := dto.ExclusionGraph{
+		Modules:  make(map[string][]string),
+		OneSided: e.OneSided(),
+		Missing:  e.Missing(),
+	}
+
+	for code := range e.titles {
+		adjacency.Modules[code] = nonNil(e.Exclusive(code))
+	}
+
+	return adjacency
+}
+
+func sortedSet(set map[string]bool) []string {
+	codes := make([]string, 0, len(set))
+	for code := range set {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+func sortedKeysOf(m map[string]map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
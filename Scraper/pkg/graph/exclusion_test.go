@@ -0,0 +1,61 @@
+package graph
+
+import (
+	"ntumods/pkg/dto"
+	"reflect"
+	"testing"
+)
+
+func exclusiveCourse(code string, mutuallyExclusive string) dto.Course {
+	return dto.Course{Code: code, Title: code, MutuallyExclusive: mutuallyExclusive}
+}
+
+func TestBuildExclusions(t *testing.T) {
+	e := BuildExclusions([]dto.Course{
+		// Only AB1201 declares AC1103
+		exclusiveCourse("AB1201", "AC1103, BF2201"),
+		exclusiveCourse("AC1103", ""),
+		// AC1104 and AC2101 declare each other
+		exclusiveCourse("AC1104", "AC2101"),
+		exclusiveCourse("AC2101", "ac1104"),
+		// Declaring itself is ignored
+		exclusiveCourse("CZ1003", "CZ1003"),
+	})
+
+	tests := []struct {
+		code     string
+		expected []string
+	}{
+		{"AB1201", []string{"AC1103", "BF2201"}},
+		{"AC1103", []string{"AB1201"}},
+		{"AC1104", []string{"AC2101"}},
+		{"AC2101", []string{"AC1104"}},
+		{"BF2201", []string{"AB1201"}},
+		{"CZ1003", []string{}},
+	}
+	for _, tt := range tests {
+		if got := e.Exclusive(tt.code); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s is exclusive with %v, expected %v", tt.code, got, tt.expected)
+		}
+	}
+
+	// BF2201 was not scraped, so there is no telling whether it declares AB1201
+	expectedOneSided := []dto.OneSidedExclusion{{Module: "AB1201", Excludes: "AC1103"}}
+	if got := e.OneSided(); !reflect.DeepEqual(got, expectedOneSided) {
+		t.Errorf("one-sided declarations are %+v, expected %+v", got, expectedOneSided)
+	}
+
+	expectedMissing := map[string][]string{"BF2201": {"AB1201"}}
+	if got := e.Missing(); !reflect.DeepEqual(got, expectedMissing) {
+		t.Errorf("missing modules are %v, expected %v", got, expectedMissing)
+	}
+
+	// Only scraped modules are keys of the published graph
+	adjacency := e.Adjacency()
+	if _, exists := adjacency.Modules["BF2201"]; exists || len(adjacency.Modules) != 5 {
+		t.Errorf("the graph has modules %v, expected the 5 scraped", adjacency.Modules)
+	}
+	if got := adjacency.Modules["CZ1003"]; got == nil || len(got) != 0 {
+		t.Errorf("CZ1003 is exclusive with %#v, expected an empty list", got)
+	}
+}
@@ -0,0 +1,296 @@
+package graph
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"ntumods/pkg/dto"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Graph is the module dependency graph built from parsed prerequisites, an edge runs from a module to
+// each module code named in its prerequisite
+type Graph struct {
+	titles   map[string]string
+	requires map[string][]string
+	unlocks  map[string][]string
+}
+
+func Build(courses []dto.Course) *Graph {
+	g := &Graph{
+		titles:   make(map[string]string),
+		requires: make(map[string][]string),
+		unlocks:  make(map[string][]string),
+	}
+
+	for _, c := range courses {
+		if c.Code == "" {
+			continue
+		}
+		g.titles[c.Code] = c.Title
+	}
+
+	for _, c := range courses {
+		if c.Code == "" {
+			continue
+		}
+
+		seen := make(map[string]bool)
+		for _, code := range PrerequisiteCodes(c.PrerequisiteTree) {
+			if seen[code] {
+				continue
+			}
+			seen[code] = true
+
+			g.requires[c.Code] = append(g.requires[c.Code], code)
+			g.unlocks[code] = append(g.unlocks[code], c.Code)
+		}
+	}
+
+	for _, edges := range []map[string][]string{g.requires, g.unlocks} {
+		for code := range edges {
+			sort.Strings(edges[code])
+		}
+	}
+
+	return g
+}
+
+// PrerequisiteCodes returns every module code named in node, in order of appearance
+func PrerequisiteCodes(node *dto.PrerequisiteNode) []string {
+	if node == nil {
+		return nil
+	}
+
+	if node.Type == dto.PREREQ_MODULE {
+		return []string{node.Code}
+	}
+
+	var codes []string
+	for _, child := range node.Children {
+		codes = append(codes, PrerequisiteCodes(child)...)
+	}
+	return codes
+}
+
+// Modules returns every scraped module code, sorted
+func (g *Graph) Modules() []string {
+	codes := make([]string, 0, len(g.titles))
+	for code := range g.titles {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Requires returns the module codes named in the prerequisite of code
+func (g *Graph) Requires(code string) []string {
+	return g.requires[code]
+}
+
+// Unlocks returns the modules whose prerequisite names code
+func (g *Graph) Unlocks(code string) []string {
+	return g.unlocks[code]
+}
+
+// AllUnlocks returns every module that directly or indirectly requires code, sorted
+func (g *Graph) AllUnlocks(code string) []string {
+	seen := map[string]bool{code: true}
+	queue := []string{code}
+	var unlocks []string
+
+	for len(queue) > 0 {
+		curr := queue[0]
+		queue = queue[1:]
+
+		for _, next := range g.unlocks[curr] {
+			if seen[next] {
+				continue
+			}
+			seen[next] = true
+			unlocks = append(unlocks, next)
+			queue = append(queue, next)
+		}
+	}
+
+	sort.Strings(unlocks)
+	return unlocks
+}
+
+// Missing maps module codes that are required but were not scraped to the modules requiring them
+func (g *Graph) Missing() map[string][]string {
+	missing := make(map[string][]string)
+	for code, unlocks := range g.unlocks {
+		if _, exists := g.titles[code]; !exists {
+			missing[code] = unlocks
+		}
+	}
+	return missing
+}
+
+// Cycles returns every group of modules that require each other, found as the strongly connected
+// components of the graph with more than one module or with a module requiring itself
+func (g *Graph) Cycles() [][]string {
+	index := 0
+	indices := make(map[string]int)
+	lowLinks := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	cycles := make([][]string, 0)
+
+	var connect func(code string)
+	connect = func(code string) {
+		indices[code] = index
+		lowLinks[code] = index
+		index++
+		stack = append(stack, code)
+		onStack[code] = true
+
+		for _, next := range g.requires[code] {
+			if _, visited := indices[next]; !visited {
+				connect(next)
+				if lowLinks[next] < lowLinks[code] {
+					lowLinks[code] = lowLinks[next]
+				}
+			} else if onStack[next] && indices[next] < lowLinks[code] {
+				lowLinks[code] = indices[next]
+			}
+		}
+
+		if lowLinks[code] != indices[code] {
+			return
+		}
+
+		var component []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == code {
+				break
+			}
+		}
+
+		if len(component) > 1 || g.requiresItself(code) {
+			sort.Strings(component)
+			cycles = append(cycles, component)
+		}
+	}
+
+	for _, code := range g.Modules() {
+		if _, visited := indices[code]; !visited {
+			connect(code)
+		}
+	}
+
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
+
+func (g *Graph) requiresItself(code string) bool {
+	for _, next := range g.requires[code] {
+		if next == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Adjacency returns the graph in the form published as prerequisiteGraph.json
+func (g *Graph) Adjacency() dto.PrerequisiteGraph {
+	adjacency := dto.PrerequisiteGraph{
+		Modules: make(map[string]dto.PrerequisiteGraphNode),
+		Cycles:  g.Cycles(),
+		Missing: g.Missing(),
+	}
+
+	for _, code := range g.Modules() {
+		adjacency.Modules[code] = dto.PrerequisiteGraphNode{
+			Title:    g.titles[code],
+			Requires: nonNil(g.requires[code]),
+			Unlocks:  nonNil(g.unlocks[code]),
+		}
+	}
+
+	return adjacency
+}
+
+// WriteDOT writes the graph in Graphviz DOT, edges point from a prerequisite to the module it unlocks
+// and modules that were not scraped are drawn dashed
+func (g *Graph) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "digraph prerequisites {")
+	fmt.Fprintln(bw, "\trankdir=LR;")
+
+	for _, code := range g.Modules() {
+		fmt.Fprintf(bw, "\t%s [label=%s];\n", strconv.Quote(code), strconv.Quote(code+"\n"+g.titles[code]))
+	}
+
+	for _, code := range sortedKeys(g.Missing()) {
+		fmt.Fprintf(bw, "\t%s [style=dashed];\n", strconv.Quote(code))
+	}
+
+	for _, code := range g.Modules() {
+		for _, prerequisite := range g.requires[code] {
+			fmt.Fprintf(bw, "\t%s -> %s;\n", strconv.Quote(prerequisite), strconv.Quote(code))
+		}
+	}
+
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// WriteGraphML writes the graph in GraphML, edges point from a prerequisite to the module it unlocks
+func (g *Graph) WriteGraphML(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, xml.Header+`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">`)
+	fmt.Fprintln(bw, `  <key id="title" for="node" attr.name="title" attr.type="string"/>`)
+	fmt.Fprintln(bw, `  <key id="missing" for="node" attr.name="missing" attr.type="boolean"/>`)
+	fmt.Fprintln(bw, `  <graph id="prerequisites" edgedefault="directed">`)
+
+	for _, code := range g.Modules() {
+		fmt.Fprintf(bw, "    <node id=\"%s\"><data key=\"title\">%s</data></node>\n", escapeXML(code), escapeXML(g.titles[code]))
+	}
+
+	for _, code := range sortedKeys(g.Missing()) {
+		fmt.Fprintf(bw, "    <node id=\"%s\"><data key=\"missing\">true</data></node>\n", escapeXML(code))
+	}
+
+	for _, code := range g.Modules() {
+		for _, prerequisite := range g.requires[code] {
+			fmt.Fprintf(bw, "    <edge source=\"%s\" target=\"%s\"/>\n", escapeXML(prerequisite), escapeXML(code))
+		}
+	}
+
+	fmt.Fprintln(bw, "  </graph>")
+	fmt.Fprintln(bw, "</graphml>")
+	return bw.Flush()
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,167 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/xml"
+	"ntumods/pkg/dto"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// course returns a module whose prerequisite is every code in requires
+func course(code string, title string, requires ...string) dto.Course {
+	c := dto.Course{Code: code, Title: title}
+	if len(requires) == 0 {
+		return c
+	}
+
+	c.PrerequisiteTree = &dto.PrerequisiteNode{Type: dto.PREREQ_AND}
+	for _, r := range requires {
+		c.PrerequisiteTree.Children = append(c.PrerequisiteTree.Children, &dto.PrerequisiteNode{Type: dto.PREREQ_MODULE, Code: r})
+	}
+	return c
+}
+
+func testGraph() *Graph {
+	return Build([]dto.Course{
+		course("AC1103", "INTRODUCTION TO FINANCIAL ACCOUNTING"),
+		course("AC1104", "FINANCIAL ACCOUNTING II", "AC1103"),
+		course("AC2101", "ACCOUNTING INFORMATION SYSTEMS", "AC1104", "AC1104"),
+		course("AC3101", "AUDITING", "AC2101", "AB1201"),
+		course("AB1202", "STATISTICS", "AC1103"),
+		// Required by each other
+		course("CZ2001", "ALGORITHMS", "CZ2002"),
+		course("CZ2002", "DATA STRUCTURES", "CZ2001"),
+		// Requires itself
+		course("CZ3005", "ARTIFICIAL INTELLIGENCE", "CZ3005"),
+	})
+}
+
+func TestRequiresAndUnlocks(t *testing.T) {
+	g := testGraph()
+
+	// A code named twice is one edge
+	if requires := g.Requires("AC2101"); !reflect.DeepEqual(requires, []string{"AC1104"}) {
+		t.Errorf("AC2101 requires %v, expected [AC1104]", requires)
+	}
+	if unlocks := g.Unlocks("AC1103"); !reflect.DeepEqual(unlocks, []string{"AB1202", "AC1104"}) {
+		t.Errorf("AC1103 unlocks %v, expected [AB1202 AC1104]", unlocks)
+	}
+}
+
+func TestAllUnlocks(t *testing.T) {
+	g := testGraph()
+
+	tests := []struct {
+		code     string
+		expected []string
+	}{
+		{"AC1103", []string{"AB1202", "AC1104", "AC2101", "AC3101"}},
+		{"AC2101", []string{"AC3101"}},
+		{"AC3101", nil},
+		// A cycle ends without listing the module itself
+		{"CZ2001", []string{"CZ2002"}},
+		{"CZ3005", nil},
+	}
+
+	for _, tt := range tests {
+		if got := g.AllUnlocks(tt.code); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("AllUnlocks(%s) is %v, expected %v", tt.code, got, tt.expected)
+		}
+	}
+}
+
+func TestMissing(t *testing.T) {
+	expected := map[string][]string{"AB1201": {"AC3101"}}
+	if missing := testGraph().Missing(); !reflect.DeepEqual(missing, expected) {
+		t.Errorf("Missing is %v, expected %v", missing, expected)
+	}
+}
+
+func TestCycles(t *testing.T) {
+	expected := [][]string{{"CZ2001", "CZ2002"}, {"CZ3005"}}
+	if cycles := testGraph().Cycles(); !reflect.DeepEqual(cycles, expected) {
+		t.Errorf("Cycles is %v, expected %v", cycles, expected)
+	}
+
+	if cycles := Build([]dto.Course{course("AC1103", ""), course("AC1104", "", "AC1103")}).Cycles(); len(cycles) != 0 {
+		t.Errorf("Cycles of an acyclic graph is %v", cycles)
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	g := Build([]dto.Course{
+		course("AC1103", `INTRODUCTION TO "FINANCIAL" ACCOUNTING`),
+		course("AC1104", `FINANCIAL ACCOUNTING \ II`, "AC1103", "AB1201"),
+	})
+
+	var b bytes.Buffer
+	if err := g.WriteDOT(&b); err != nil {
+		t.Fatal(err)
+	}
+	dot := b.String()
+
+	for _, line := range []string{
+		`"AC1103" [label="AC1103\nINTRODUCTION TO \"FINANCIAL\" ACCOUNTING"];`,
+		`"AC1104" [label="AC1104\nFINANCIAL ACCOUNTING \\ II"];`,
+		`"AB1201" [style=dashed];`,
+		`"AC1103" -> "AC1104";`,
+		`"AB1201" -> "AC1104";`,
+	} {
+		if !strings.Contains(dot, "\t"+line+"\n") {
+			t.Errorf("the DOT output has no line %s:\n%s", line, dot)
+		}
+	}
+	if !strings.HasPrefix(dot, "digraph prerequisites {\n") || !strings.HasSuffix(dot, "}\n") {
+		t.Errorf("the DOT output is not one digraph:\n%s", dot)
+	}
+}
+
+func TestWriteGraphML(t *testing.T) {
+	g := Build([]dto.Course{
+		course("AC1103", `ACCOUNTING & <FINANCE> "I"`),
+		course("AC1104", "FINANCIAL ACCOUNTING II", "AC1103", "AB1201"),
+	})
+
+	var b bytes.Buffer
+	if err := g.WriteGraphML(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	var graphML struct {
+		Nodes []struct {
+			ID   string `xml:"id,attr"`
+			Data []struct {
+				Key   string `xml:"key,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"data"`
+		} `xml:"graph>node"`
+		Edges []struct {
+			Source string `xml:"source,attr"`
+			Target string `xml:"target,attr"`
+		} `xml:"graph>edge"`
+	}
+	if err := xml.Unmarshal(b.Bytes(), &graphML); err != nil {
+		t.Fatalf("the GraphML output is not valid XML: %v\n%s", err, b.String())
+	}
+
+	data := make(map[string]string)
+	for _, node := range graphML.Nodes {
+		for _, d := range node.Data {
+			data[node.ID+" "+d.Key] = d.Value
+		}
+	}
+	expected := map[string]string{
+		"AC1103 title":   `ACCOUNTING & <FINANCE> "I"`,
+		"AC1104 title":   "FINANCIAL ACCOUNTING II",
+		"AB1201 missing": "true",
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("nodes are %v, expected %v", data, expected)
+	}
+
+	if len(graphML.Edges) != 2 || graphML.Edges[0].Source != "AB1201" || graphML.Edges[1].Source != "AC1103" || graphML.Edges[1].Target != "AC1104" {
+		t.Errorf("edges are %+v, expected AB1201 and AC1103 to AC1104", graphML.Edges)
+	}
+}
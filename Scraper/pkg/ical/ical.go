@@ -0,0 +1,173 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"ntumods/pkg/dto"
+	"strings"
+	"time"
+)
+
+const timestampLayout = "20060102T150405Z"
+
+// Selection is a module together with the index chosen for it
+type Selection struct {
+	Module dto.Combined
+	Index  string
+}
+
+// Export writes selections as an iCalendar file. Each class of the chosen index becomes a weekly event that
+// only falls on its teaching weeks, and each exam becomes a one-off event.
+func Export(w io.Writer, selections []Selection, calendar dto.AcademicCalendar) error {
+	bw := bufio.NewWriter(w)
+	now := time.Now().UTC().Format(timestampLayout)
+
+	writeLine(bw, "BEGIN:VCALENDAR")
+	writeLine(bw, "VERSION:2.0")
+	writeLine(bw, "PRODID:-//ntumods//scraper//EN")
+	writeLine(bw, "CALSCALE:GREGORIAN")
+	writeLine(bw, "X-WR-CALNAME:"+escapeText(calendar.AcadYearSem+" Timetable"))
+	writeLine(bw, "X-WR-TIMEZONE:Asia/Singapore")
+
+	for _, selection := range selections {
+		m := selection.Module
+
+		found := false
+		for _, s := range m.Schedule {
+			if s.Index != selection.Index {
+				continue
+			}
+			found = true
+
+			if err := writeClass(bw, m, s, calendar, now); err != nil {
+				return fmt.Errorf("[ical.Export] %s %s %s: %v", m.Code, s.ClassType, s.DayOfWeek, err)
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("[ical.Export] Index %s was not found in %s", selection.Index, m.Code)
+		}
+
+		if err := writeExam(bw, m, calendar, now); err != nil {
+			return fmt.Errorf("[ical.Export] %s exam: %v", m.Code, err)
+		}
+	}
+
+	writeLine(bw, "END:VCALENDAR")
+	return bw.Flush()
+}
+
+func writeClass(w *bufio.Writer, m dto.Combined, s dto.Schedule, calendar dto.AcademicCalendar, now string) error {
+	// Classes without a time or outside teaching weeks have nothing to put on a calendar
+	if len(s.TeachingWeeks) == 0 || s.StartTime == "" || s.EndTime == "" {
+		return nil
+	}
+
+	dates, err := calendar.ClassDates(s.DayOfWeek, s.TeachingWeeks)
+	if err != nil {
+		return err
+	}
+
+	start, err := atClock(dates[0], s.StartTime)
+	if err != nil {
+		return err
+	}
+	end, err := atClock(dates[0], s.EndTime)
+	if err != nil {
+		return err
+	}
+	last, err := atClock(dates[len(dates)-1], s.StartTime)
+	if err != nil {
+		return err
+	}
+
+	// The weekly rule covers every week from the first class to the last, exclude the weeks without a class
+	taught := make(map[string]bool)
+	for _, d := range dates {
+		taught[d.Format("2006-01-02")] = true
+	}
+
+	var excluded []string
+	for d := start; !d.After(last); d = d.AddDate(0, 0, 7) {
+		if !taught[d.Format("2006-01-02")] {
+			excluded = append(excluded, d.UTC().Format(timestampLayout))
+		}
+	}
+
+	uid := strings.Join([]string{calendar.AcadYearSem, m.Code, s.Index, s.ClassType, s.IndexGroup, s.DayOfWeek, s.StartTime}, "-")
+
+	writeLine(w, "BEGIN:VEVENT")
+	writeLine(w, "UID:"+escapeText(strings.ReplaceAll(uid, "/", "_")+"@ntumods"))
+	writeLine(w, "DTSTAMP:"+now)
+	writeLine(w, "DTSTART:"+start.UTC().Format(timestampLayout))
+	writeLine(w, "DTEND:"+end.UTC().Format(timestampLayout))
+	writeLine(w, "RRULE:FREQ=WEEKLY;UNTIL="+last.UTC().Format(timestampLayout))
+	if len(excluded) > 0 {
+		writeLine(w, "EXDATE:"+strings.Join(excluded, ","))
+	}
+	writeLine(w, "SUMMARY:"+escapeText(m.Code+" "+s.ClassType))
+	writeLine(w, "LOCATION:"+escapeText(s.Venue))
+	writeLine(w, "CATEGORIES:"+escapeText(s.ClassType))
+	writeLine(w, "DESCRIPTION:"+escapeText(fmt.Sprintf("%s\nIndex %s (%s)\n%s", m.Title, s.Index, s.IndexGroup, s.Remarks)))
+	writeLine(w, "END:VEVENT")
+
+	return nil
+}
+
+func writeExam(w *bufio.Writer, m dto.Combined, calendar dto.AcademicCalendar, now string) error {
+	if m.Exam.Start == "" || m.Exam.End == "" {
+		return nil
+	}
+
+	start, err := time.Parse(time.RFC3339, m.Exam.Start)
+	if err != nil {
+		return err
+	}
+	end, err := time.Parse(time.RFC3339, m.Exam.End)
+	if err != nil {
+		return err
+	}
+
+	writeLine(w, "BEGIN:VEVENT")
+	writeLine(w, "UID:"+escapeText(calendar.AcadYearSem+"-"+m.Code+"-exam@ntumods"))
+	writeLine(w, "DTSTAMP:"+now)
+	writeLine(w, "DTSTART:"+start.UTC().Format(timestampLayout))
+	writeLine(w, "DTEND:"+end.UTC().Format(timestampLayout))
+	writeLine(w, "SUMMARY:"+escapeText(m.Code+" Exam"))
+	writeLine(w, "CATEGORIES:EXAM")
+	writeLine(w, "DESCRIPTION:"+escapeText(m.Title))
+	writeLine(w, "END:VEVENT")
+
+	return nil
+}
+
+// atClock returns date at a class time such as "0830" in Asia/Singapore
+func atClock(date time.Time, clock string) (time.Time, error) {
+	t, err := time.Parse("1504", strings.TrimSpace(clock))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unrecognised class time %q", clock)
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), t.Hour(), t.Minute(), 0, 0, dto.SINGAPORE), nil
+}
+
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// writeLine writes a content line, folding it at 75 octets as required by RFC 5545
+func writeLine(w *bufio.Writer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		// Do not split a multi-byte UTF-8 character
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts towards their 75 octets
+		limit = 74
+	}
+	w.WriteString(line + "\r\n")
+}
@@ -0,0 +1,171 @@
+package ical
+
+import (
+	"bufio"
+	"bytes"
+	"ntumods/pkg/dto"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+var testCalendar = dto.AcademicCalendar{AcadYearSem: "2023_1", StartDate: "2023-08-14", RecessAfterWeek: 7}
+
+func testModule() dto.Combined {
+	var m dto.Combined
+	m.Code = "AB1201"
+	m.Title = "FINANCIAL MANAGEMENT"
+	m.Schedule = []dto.Schedule{
+		// Not taught in weeks 3 and 10, nor in the recess week after week 7
+		{Index: "00101", IndexGroup: "LE1", ClassType: "LEC", DayOfWeek: "MON", StartTime: "0830", EndTime: "1020",
+			Venue: `LT1, NS; Block \ A`, Remarks: "Online", TeachingWeeks: []int{1, 2, 4, 5, 6, 7, 8, 9, 11, 12, 13}},
+		{Index: "00102", IndexGroup: "LE2", ClassType: "LEC", DayOfWeek: "TUE", StartTime: "0830", EndTime: "1020",
+			TeachingWeeks: []int{1, 2, 3}},
+	}
+	m.Exam = dto.ExamSchedule{Code: "AB1201", Start: "2023-11-27T09:00:00+08:00", End: "2023-11-27T11:00:00+08:00"}
+	return m
+}
+
+// unfold returns the content lines of an iCalendar file, checking that every physical line is at most 75 octets
+// and ends with CRLF
+func unfold(t *testing.T, data string) []string {
+	if !strings.HasSuffix(data, "\r\n") {
+		t.Fatalf("the file does not end with CRLF")
+	}
+
+	var lines []string
+	for _, physical := range strings.Split(strings.TrimSuffix(data, "\r\n"), "\r\n") {
+		if len(physical) > 75 {
+			t.Errorf("the line %q is %d octets", physical, len(physical))
+		}
+		if !utf8.ValidString(physical) {
+			t.Errorf("the line %q splits a character", physical)
+		}
+
+		if strings.HasPrefix(physical, " ") && len(lines) > 0 {
+			lines[len(lines)-1] += physical[1:]
+		} else {
+			lines = append(lines, physical)
+		}
+	}
+	return lines
+}
+
+// event returns the properties of the event whose UID starts with uid
+func event(lines []string, uid string) map[string]string {
+	var properties map[string]string
+	for _, line := range lines {
+		switch {
+		case line == "BEGIN:VEVENT":
+			properties = make(map[string]string)
+		case line == "END:VEVENT":
+			if strings.HasPrefix(properties["UID"], uid) {
+				return properties
+			}
+			properties = nil
+		case properties != nil:
+			parts := strings.SplitN(line, ":", 2)
+			properties[parts[0]] = parts[1]
+		}
+	}
+	return nil
+}
+
+func TestExport(t *testing.T) {
+	var b bytes.Buffer
+	if err := Export(&b, []Selection{{Module: testModule(), Index: "00101"}}, testCalendar); err != nil {
+		t.Fatal(err)
+	}
+	lines := unfold(t, b.String())
+
+	if lines[0] != "BEGIN:VCALENDAR" || lines[len(lines)-1] != "END:VCALENDAR" {
+		t.Errorf("the file is not one VCALENDAR: %q", lines)
+	}
+	if strings.Contains(b.String(), "LE2") {
+		t.Error("a class of another index was exported")
+	}
+
+	class := event(lines, "2023_1-AB1201-00101-LEC")
+	if class == nil {
+		t.Fatalf("no event for the lecture of index 00101 in %q", lines)
+	}
+
+	// 8.30am in Singapore is 0.30am UTC, the last class is on the Monday of week 13
+	expected := map[string]string{
+		"DTSTART": "20230814T003000Z",
+		"DTEND":   "20230814T022000Z",
+		"RRULE":   "FREQ=WEEKLY;UNTIL=20231113T003000Z",
+		// Week 3, the recess week and week 10
+		"EXDATE":      "20230828T003000Z,20231002T003000Z,20231023T003000Z",
+		"SUMMARY":     "AB1201 LEC",
+		"LOCATION":    `LT1\, NS\; Block \\ A`,
+		"DESCRIPTION": `FINANCIAL MANAGEMENT\nIndex 00101 (LE1)\nOnline`,
+	}
+	for property, value := range expected {
+		if class[property] != value {
+			t.Errorf("%s is %q, expected %q", property, class[property], value)
+		}
+	}
+
+	exam := event(lines, "2023_1-AB1201-exam")
+	if exam == nil || exam["DTSTART"] != "20231127T010000Z" || exam["DTEND"] != "20231127T030000Z" {
+		t.Errorf("the exam event is %v", exam)
+	}
+}
+
+func TestExportWithoutExcludedWeeks(t *testing.T) {
+	var b bytes.Buffer
+	if err := Export(&b, []Selection{{Module: testModule(), Index: "00102"}}, testCalendar); err != nil {
+		t.Fatal(err)
+	}
+
+	class := event(unfold(t, b.String()), "2023_1-AB1201-00102-LEC")
+	if class == nil || class["RRULE"] != "FREQ=WEEKLY;UNTIL=20230829T003000Z" {
+		t.Errorf("the lecture is %v, expected it to repeat until week 3", class)
+	}
+	if _, exists := class["EXDATE"]; exists {
+		t.Errorf("EXDATE is %q for a class in consecutive weeks", class["EXDATE"])
+	}
+}
+
+func TestExportUnknownIndex(t *testing.T) {
+	var b bytes.Buffer
+	err := Export(&b, []Selection{{Module: testModule(), Index: "99999"}}, testCalendar)
+	if err == nil || !strings.Contains(err.Error(), "Index 99999 was not found in AB1201") {
+		t.Errorf("Export returned %v, expected index 99999 not to be found", err)
+	}
+}
+
+func TestWriteLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected []string
+	}{
+		{"short", "SUMMARY:AB1201 LEC", []string{"SUMMARY:AB1201 LEC"}},
+		{"75 octets", strings.Repeat("a", 75), []string{strings.Repeat("a", 75)}},
+		{"76 octets", strings.Repeat("a", 76), []string{strings.Repeat("a", 75), " a"}},
+		// The 75th octet is the first of "é", which moves to the next line
+		{"multi-byte", strings.Repeat("a", 74) + "éb", []string{strings.Repeat("a", 74), " éb"}},
+		// Continuation lines hold 74 octets after the space
+		{"three lines", strings.Repeat("a", 75+74+1), []string{strings.Repeat("a", 75), " " + strings.Repeat("a", 74), " a"}},
+		// The 75th octet is the second of a character of three octets
+		{"characters of three octets", "SUMMARY:" + strings.Repeat("金融", 20), []string{
+			"SUMMARY:" + strings.Repeat("金融", 11),
+			" " + strings.Repeat("金融", 9),
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			w := bufio.NewWriter(&b)
+			writeLine(w, tt.line)
+			w.Flush()
+
+			if got, expected := b.String(), strings.Join(tt.expected, "\r\n")+"\r\n"; got != expected {
+				t.Errorf("writeLine wrote %q, expected %q", got, expected)
+			}
+		})
+	}
+}
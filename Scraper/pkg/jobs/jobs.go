@@ -0,0 +1,218 @@
+package jobs
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"ntumods/pkg/dto"
+	"sync"
+	"time"
+)
+
+// ErrConflict fails a job when one of its semesters is already being scraped by another job
+var ErrConflict = errors.New("semester is already being scraped")
+
+// ResolveFunc turns the semester selection of a job, e.g. "latest", into the semesters to scrape
+type ResolveFunc func(selection string) ([]string, error)
+
+// RunFunc runs job, reporting its progress through progress
+type RunFunc func(job dto.Job, progress *Progress) error
+
+// Manager runs scrape jobs in the background, allowing only one job at a time for each semester
+type Manager struct {
+	resolve ResolveFunc
+	run     RunFunc
+
+	mu       sync.Mutex
+	jobs     map[string]*dto.Job
+	progress map[string]*Progress
+	// active maps each semester being scraped to the ID of its job
+	active map[string]string
+	// finished lists the IDs of the finished jobs, oldest first
+	finished []finishedJob
+
+	retention   time.Duration
+	maxFinished int
+	now         func() time.Time
+}
+
+type finishedJob struct {
+	id string
+	at time.Time
+}
+
+// Progress is the progress of a running job, it is safe for concurrent use and a nil Progress ignores updates
+type Progress struct {
+	mu       sync.Mutex
+	progress dto.JobProgress
+}
+
+func NewManager(resolve ResolveFunc, run RunFunc) *Manager {
+	return &Manager{
+		resolve:  resolve,
+		run:      run,
+		jobs:     make(map[string]*dto.Job),
+		progress: make(map[string]*Progress),
+		active:   make(map[string]string),
+
+		retention:   dto.JOB_RETENTION,
+		maxFinished: dto.MAX_FINISHED_JOBS,
+		now:         time.Now,
+	}
+}
+
+// Submit queues a job scraping the semesters in selection and returns it straight away. The selection is resolved
+// in the background as it may take a request to WIS, a selection that cannot be resolved or that names a semester
+// being scraped by another job fails the job.
+func (m *Manager) Submit(selection string, scope []string, dryRun bool) (dto.Job, error) {
+	id, err := newID()
+	if err != nil {
+		return dto.Job{}, fmt.Errorf("[Submit] Failed to generate job ID: %v", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.evict()
+
+	job := &dto.Job{
+		ID:        id,
+		Selection: selection,
+		Semesters: []string{},
+		Scope:     scope,
+		DryRun:    dryRun,
+		Status:    dto.JOB_QUEUED,
+		CreatedAt: m.now().Format(time.RFC3339),
+	}
+	progress := &Progress{}
+
+	m.jobs[id] = job
+	m.progress[id] = progress
+
+	go m.execute(job, progress)
+
+	return *job, nil
+}
+
+// Get returns the job with id along with its latest progress
+func (m *Manager) Get(id string) (dto.Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, exists := m.jobs[id]
+	if !exists {
+		return dto.Job{}, false
+	}
+
+	snapshot := *job
+	snapshot.Progress = m.progress[id].Snapshot()
+	return snapshot, true
+}
+
+func (m *Manager) execute(job *dto.Job, progress *Progress) {
+	semesters, err := m.resolve(job.Selection)
+	if err == nil && len(semesters) == 0 {
+		err = fmt.Errorf("[execute] No semesters to scrape")
+	}
+
+	m.mu.Lock()
+	if err == nil {
+		err = m.claim(job, semesters)
+	}
+	if err != nil {
+		m.finish(job, err)
+		m.mu.Unlock()
+		return
+	}
+
+	job.Status = dto.JOB_RUNNING
+	job.StartedAt = m.now().Format(time.RFC3339)
+	snapshot := *job
+	m.mu.Unlock()
+
+	err = m.run(snapshot, progress)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.finish(job, err)
+}
+
+// claim marks semesters as being scraped by job, unless another job is scraping any of them. m.mu must be held.
+func (m *Manager) claim(job *dto.Job, semesters []string) error {
+	for _, semester := range semesters {
+		if other, exists := m.active[semester]; exists {
+			return fmt.Errorf("%w: %s by job %s", ErrConflict, semester, other)
+		}
+	}
+
+	job.Semesters = semesters
+	for _, semester := range semesters {
+		m.active[semester] = job.ID
+	}
+	return nil
+}
+
+// finish records the outcome of job and frees its semesters. m.mu must be held.
+func (m *Manager) finish(job *dto.Job, err error) {
+	finishedAt := m.now()
+	job.FinishedAt = finishedAt.Format(time.RFC3339)
+	if err != nil {
+		job.Status = dto.JOB_FAILED
+		job.Error = err.Error()
+	} else {
+		job.Status = dto.JOB_SUCCEEDED
+	}
+
+	for _, semester := range job.Semesters {
+		if m.active[semester] == job.ID {
+			delete(m.active, semester)
+		}
+	}
+
+	m.finished = append(m.finished, finishedJob{id: job.ID, at: finishedAt})
+	m.evict()
+}
+
+// evict forgets the finished jobs past the retention period, and the oldest ones beyond maxFinished, so that a
+// long running server does not keep every job it ever ran. Running jobs are never evicted. m.mu must be held.
+func (m *Manager) evict() {
+	cutoff := m.now().Add(-m.retention)
+
+	n := 0
+	for n < len(m.finished) && (len(m.finished)-n > m.maxFinished || m.finished[n].at.Before(cutoff)) {
+		delete(m.jobs, m.finished[n].id)
+		delete(m.progress, m.finished[n].id)
+		n++
+	}
+	m.finished = m.finished[n:]
+}
+
+// Update applies update to the progress
+func (p *Progress) Update(update func(progress *dto.JobProgress)) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	update(&p.progress)
+}
+
+func (p *Progress) Snapshot() dto.JobProgress {
+	if p == nil {
+		return dto.JobProgress{}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.progress
+}
+
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
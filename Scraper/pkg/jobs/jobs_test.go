@@ -0,0 +1,213 @@
+package jobs
+
+import (
+	"errors"
+	"ntumods/pkg/dto"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// clock is a time that only moves when told to
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// listed resolves a comma separated selection to the semesters it lists
+func listed(selection string) ([]string, error) {
+	if selection == "" {
+		return nil, nil
+	}
+	return strings.Split(selection, ","), nil
+}
+
+func newTestManager(run RunFunc) (*Manager, *clock) {
+	c := &clock{now: time.Date(2023, 8, 14, 8, 0, 0, 0, time.UTC)}
+	m := NewManager(listed, run)
+	m.now = c.Now
+	return m, c
+}
+
+// submitAndWait submits a job for selection and waits for it to finish
+func submitAndWait(t *testing.T, m *Manager, selection string) dto.Job {
+	job, err := m.Submit(selection, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return waitFor(t, m, job.ID, dto.JOB_SUCCEEDED, dto.JOB_FAILED)
+}
+
+// waitFor waits for the job with id to reach any of statuses
+func waitFor(t *testing.T, m *Manager, id string, statuses ...string) dto.Job {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		got, _ := m.Get(id)
+		for _, status := range statuses {
+			if got.Status == status {
+				return got
+			}
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("job %s did not reach %v", id, statuses)
+	return dto.Job{}
+}
+
+func succeed(job dto.Job, progress *Progress) error {
+	return nil
+}
+
+func TestManagerRunsJob(t *testing.T) {
+	m, _ := newTestManager(func(job dto.Job, progress *Progress) error {
+		progress.Update(func(p *dto.JobProgress) { p.SemestersDone++ })
+		return errors.New("WIS is down")
+	})
+
+	job := submitAndWait(t, m, "2023_1")
+	if job.Status != dto.JOB_FAILED || job.Error != "WIS is down" || job.Progress.SemestersDone != 1 || !reflect.DeepEqual(job.Semesters, []string{"2023_1"}) {
+		t.Errorf("job is %+v, expected it to have failed after one semester", job)
+	}
+
+	// The semester is free again once the job finished
+	submitAndWait(t, m, "2023_1")
+}
+
+func TestManagerConflict(t *testing.T) {
+	release := make(chan struct{})
+	m, _ := newTestManager(func(job dto.Job, progress *Progress) error {
+		if job.Semesters[0] == "2023_1" {
+			<-release
+		}
+		return nil
+	})
+	defer close(release)
+
+	first, err := m.Submit("2023_1", nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, m, first.ID, dto.JOB_RUNNING)
+
+	// The conflict is only known once the selection is resolved, so the job is accepted and then fails
+	job := submitAndWait(t, m, "2023_2,2023_1")
+	if job.Status != dto.JOB_FAILED || !strings.Contains(job.Error, ErrConflict.Error()) || job.StartedAt != "" {
+		t.Errorf("job is %+v, expected it to fail with a conflict before starting", job)
+	}
+
+	// The job that failed did not hold on to 2023_2
+	if job = submitAndWait(t, m, "2023_2"); job.Status != dto.JOB_SUCCEEDED {
+		t.Errorf("job is %+v, expected 2023_2 to be free", job)
+	}
+}
+
+func TestManagerResolve(t *testing.T) {
+	resolving := make(chan struct{})
+	release := make(chan struct{})
+	m := NewManager(func(selection string) ([]string, error) {
+		close(resolving)
+		<-release
+		return []string{"2023_1"}, nil
+	}, succeed)
+
+	// Submit returns before the selection is resolved
+	job, err := m.Submit("latest", nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-resolving
+	if got, _ := m.Get(job.ID); got.Status != dto.JOB_QUEUED || got.Selection != "latest" || len(got.Semesters) != 0 {
+		t.Errorf("job is %+v while resolving, expected it to be queued", got)
+	}
+	close(release)
+
+	if got := waitFor(t, m, job.ID, dto.JOB_SUCCEEDED, dto.JOB_FAILED); got.Status != dto.JOB_SUCCEEDED || !reflect.DeepEqual(got.Semesters, []string{"2023_1"}) {
+		t.Errorf("job is %+v, expected it to scrape 2023_1", got)
+	}
+}
+
+func TestManagerResolveError(t *testing.T) {
+	m, _ := newTestManager(succeed)
+	m.resolve = func(selection string) ([]string, error) {
+		return nil, errors.New("semester 2030_1 is not offered")
+	}
+
+	job := submitAndWait(t, m, "2030_1")
+	if job.Status != dto.JOB_FAILED || job.Error != "semester 2030_1 is not offered" {
+		t.Errorf("job is %+v, expected it to fail resolving", job)
+	}
+
+	if job = submitAndWait(t, NewManager(listed, succeed), ""); job.Status != dto.JOB_FAILED {
+		t.Errorf("job is %+v, expected a job without semesters to fail", job)
+	}
+}
+
+func TestManagerEvictsByCount(t *testing.T) {
+	m, _ := newTestManager(succeed)
+	m.maxFinished = 2
+
+	first := submitAndWait(t, m, "2023_1")
+	second := submitAndWait(t, m, "2023_1")
+	third := submitAndWait(t, m, "2023_1")
+
+	if _, exists := m.Get(first.ID); exists {
+		t.Error("the oldest finished job was kept")
+	}
+	for _, job := range []dto.Job{second, third} {
+		if _, exists := m.Get(job.ID); !exists {
+			t.Errorf("job %s was evicted", job.ID)
+		}
+	}
+	if len(m.jobs) != 2 || len(m.progress) != 2 {
+		t.Errorf("%d jobs and %d progresses are kept, expected 2", len(m.jobs), len(m.progress))
+	}
+}
+
+func TestManagerEvictsByAge(t *testing.T) {
+	release := make(chan struct{})
+	m, c := newTestManager(func(job dto.Job, progress *Progress) error {
+		if job.Semesters[0] == "2023_2" {
+			<-release
+		}
+		return nil
+	})
+	defer close(release)
+
+	old := submitAndWait(t, m, "2023_1")
+
+	running, err := m.Submit("2023_2", nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.Advance(dto.JOB_RETENTION - time.Minute)
+	recent := submitAndWait(t, m, "2023_1")
+
+	c.Advance(2 * time.Minute)
+	// Submitting evicts the jobs past the retention period
+	submitAndWait(t, m, "2023_S")
+
+	if _, exists := m.Get(old.ID); exists {
+		t.Error("a job finished longer than the retention period ago was kept")
+	}
+	if _, exists := m.Get(recent.ID); !exists {
+		t.Error("a job finished within the retention period was evicted")
+	}
+	if job, exists := m.Get(running.ID); !exists || job.FinishedAt != "" {
+		t.Errorf("the unfinished job is %+v, exists = %v", job, exists)
+	}
+}
@@ -0,0 +1,116 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"ntumods/pkg/dto"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var examDateLayouts = []string{
+	"2 January 2006",
+	"2 Jan 2006",
+	"2-Jan-2006",
+	"2-January-2006",
+	"2/1/2006",
+	"2006-01-02",
+	"January 2, 2006",
+	"Jan 2, 2006",
+}
+
+var examTimeLayouts = []string{
+	"3:04 pm",
+	"3:04pm",
+	"3 pm",
+	"3pm",
+	"15:04",
+	"1504",
+}
+
+var (
+	// "9.00 am" and "9.00 a.m." are rewritten as "9:00 am"
+	examTimeDotPattern = regexp.MustCompile(`(\d)\.(\d\d)`)
+	// The unit may be followed straight away by the minutes, as in "2h30m", where \b would not match
+	durationHourPattern   = regexp.MustCompile(`(?i)(\d+(?:\.\d+)?)\s*(?:h|hr|hrs|hour|hours)(?:[^a-z]|$)`)
+	durationMinutePattern = regexp.MustCompile(`(?i)(\d+)\s*(?:m|min|mins|minute|minutes)\b`)
+	durationNumberPattern = regexp.MustCompile(`^\d+(?:\.\d+)?$`)
+)
+
+// ParseExamDateTime parses the date and time columns of the exam timetable, e.g. "27 November 2023" and
+// "9.00 am", as a time in Asia/Singapore
+func ParseExamDateTime(date string, clock string) (time.Time, error) {
+	date = strings.Join(strings.Fields(date), " ")
+
+	var day time.Time
+	var err error
+	for _, layout := range examDateLayouts {
+		if day, err = time.ParseInLocation(layout, date, dto.SINGAPORE); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unrecognised exam date %q", date)
+	}
+
+	clock = strings.ToLower(strings.Join(strings.Fields(clock), " "))
+	clock = strings.NewReplacer("a.m.", "am", "p.m.", "pm").Replace(clock)
+	clock = examTimeDotPattern.ReplaceAllString(clock, "$1:$2")
+
+	for _, layout := range examTimeLayouts {
+		if t, err := time.Parse(layout, clock); err == nil {
+			return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, dto.SINGAPORE), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognised exam time %q", clock)
+}
+
+// ParseDurationMinutes parses the duration column of the exam timetable, e.g. "2 hr 30 min", "2.5 hrs",
+// "90 min" or a bare "2.5" which is taken to be in hours
+func ParseDurationMinutes(duration string) (int, error) {
+	duration = strings.TrimSpace(duration)
+
+	if durationNumberPattern.MatchString(duration) {
+		hours, _ := strconv.ParseFloat(duration, 64)
+		return int(math.Round(hours * 60)), nil
+	}
+
+	hourMatches := durationHourPattern.FindAllStringSubmatch(duration, -1)
+	minuteMatches := durationMinutePattern.FindAllStringSubmatch(duration, -1)
+	if len(hourMatches) == 0 && len(minuteMatches) == 0 {
+		return 0, fmt.Errorf("unrecognised exam duration %q", duration)
+	}
+
+	minutes := 0.0
+	for _, m := range hourMatches {
+		hours, _ := strconv.ParseFloat(m[1], 64)
+		minutes += hours * 60
+	}
+	for _, m := range minuteMatches {
+		mins, _ := strconv.ParseFloat(m[1], 64)
+		minutes += mins
+	}
+
+	return int(math.Round(minutes)), nil
+}
+
+// populateExamTimes fills in Start, End and DurationMinutes from the raw columns of exam
+func populateExamTimes(exam *dto.ExamSchedule) error {
+	start, err := ParseExamDateTime(exam.Date, exam.Time)
+	if err != nil {
+		return err
+	}
+
+	minutes, err := ParseDurationMinutes(exam.Duration)
+	if err != nil {
+		return err
+	}
+
+	exam.Start = start.Format(time.RFC3339)
+	exam.End = start.Add(time.Duration(minutes) * time.Minute).Format(time.RFC3339)
+	exam.DurationMinutes = minutes
+	return nil
+}
@@ -0,0 +1,127 @@
+package parser
+
+import (
+	"ntumods/pkg/dto"
+	"testing"
+	"time"
+)
+
+func TestParseExamDateTime(t *testing.T) {
+	tests := []struct {
+		date     string
+		clock    string
+		expected string
+	}{
+		// Every date layout
+		{"27 November 2023", "9.00 am", "2023-11-27T09:00:00+08:00"},
+		{"27 Nov 2023", "9.00 am", "2023-11-27T09:00:00+08:00"},
+		{"27-Nov-2023", "9.00 am", "2023-11-27T09:00:00+08:00"},
+		{"27-November-2023", "9.00 am", "2023-11-27T09:00:00+08:00"},
+		{"27/11/2023", "9.00 am", "2023-11-27T09:00:00+08:00"},
+		{"2023-11-27", "9.00 am", "2023-11-27T09:00:00+08:00"},
+		{"November 27, 2023", "9.00 am", "2023-11-27T09:00:00+08:00"},
+		{"Nov 27, 2023", "9.00 am", "2023-11-27T09:00:00+08:00"},
+		{" 27   November  2023 ", "9.00 am", "2023-11-27T09:00:00+08:00"},
+		// Every time layout
+		{"27 November 2023", "1:30 pm", "2023-11-27T13:30:00+08:00"},
+		{"27 November 2023", "1:30pm", "2023-11-27T13:30:00+08:00"},
+		{"27 November 2023", "1 pm", "2023-11-27T13:00:00+08:00"},
+		{"27 November 2023", "1pm", "2023-11-27T13:00:00+08:00"},
+		{"27 November 2023", "13:30", "2023-11-27T13:30:00+08:00"},
+		{"27 November 2023", "1330", "2023-11-27T13:30:00+08:00"},
+		{"27 November 2023", "9.00 a.m.", "2023-11-27T09:00:00+08:00"},
+		{"27 November 2023", "5.00 P.M.", "2023-11-27T17:00:00+08:00"},
+		{"27 November 2023", "12.00 pm", "2023-11-27T12:00:00+08:00"},
+		{"27 November 2023", "12.00 am", "2023-11-27T00:00:00+08:00"},
+	}
+
+	for _, tt := range tests {
+		start, err := ParseExamDateTime(tt.date, tt.clock)
+		if err != nil {
+			t.Errorf("ParseExamDateTime(%q, %q) returned %v", tt.date, tt.clock, err)
+			continue
+		}
+		if got := start.Format(time.RFC3339); got != tt.expected {
+			t.Errorf("ParseExamDateTime(%q, %q) is %s, expected %s", tt.date, tt.clock, got, tt.expected)
+		}
+	}
+}
+
+func TestParseExamDateTimeInvalid(t *testing.T) {
+	tests := []struct {
+		date  string
+		clock string
+	}{
+		{"", "9.00 am"},
+		{"31 February 2023", "9.00 am"},
+		{"Monday", "9.00 am"},
+		{"27 November 2023", ""},
+		{"27 November 2023", "morning"},
+		{"27 November 2023", "25:00"},
+	}
+
+	for _, tt := range tests {
+		if start, err := ParseExamDateTime(tt.date, tt.clock); err == nil {
+			t.Errorf("ParseExamDateTime(%q, %q) is %s, expected an error", tt.date, tt.clock, start)
+		}
+	}
+}
+
+func TestParseDurationMinutes(t *testing.T) {
+	tests := []struct {
+		duration string
+		expected int
+	}{
+		{"2", 120},
+		{"2.5", 150},
+		{" 1.5 ", 90},
+		{"2 h", 120},
+		{"2 hr", 120},
+		{"2 hrs", 120},
+		{"1 hour", 60},
+		{"2 hours", 120},
+		{"2.5 hrs", 150},
+		{"2 HRS", 120},
+		{"90 m", 90},
+		{"90 min", 90},
+		{"90 mins", 90},
+		{"1 minute", 1},
+		{"90 minutes", 90},
+		{"2 hr 30 min", 150},
+		{"2 hours 30 minutes", 150},
+		{"2h30m", 150},
+		{"2hr30min", 150},
+		{"2hrs30mins", 150},
+		{"1h 15m", 75},
+		{"2h", 120},
+		{"(2 hrs)", 120},
+	}
+
+	for _, tt := range tests {
+		minutes, err := ParseDurationMinutes(tt.duration)
+		if err != nil {
+			t.Errorf("ParseDurationMinutes(%q) returned %v", tt.duration, err)
+			continue
+		}
+		if minutes != tt.expected {
+			t.Errorf("ParseDurationMinutes(%q) is %d, expected %d", tt.duration, minutes, tt.expected)
+		}
+	}
+
+	for _, duration := range []string{"", "two hours", "2 days", "h"} {
+		if minutes, err := ParseDurationMinutes(duration); err == nil {
+			t.Errorf("ParseDurationMinutes(%q) is %d, expected an error", duration, minutes)
+		}
+	}
+}
+
+func TestPopulateExamTimes(t *testing.T) {
+	exam := dto.ExamSchedule{Date: "27 November 2023", Time: "5.00 pm", Duration: "2h30m"}
+	if err := populateExamTimes(&exam); err != nil {
+		t.Fatal(err)
+	}
+
+	if exam.Start != "2023-11-27T17:00:00+08:00" || exam.End != "2023-11-27T19:30:00+08:00" || exam.DurationMinutes != 150 {
+		t.Errorf("exam is %s to %s, %d minutes", exam.Start, exam.End, exam.DurationMinutes)
+	}
+}
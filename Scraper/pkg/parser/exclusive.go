@@ -0,0 +1,23 @@
+package parser
+
+import "strings"
+
+// ParseMutuallyExclusive returns the module codes in raw, e.g. "AB8401, BC0401", in order of appearance and without
+// duplicates. Anything that is not a module code is dropped.
+func ParseMutuallyExclusive(raw string) []string {
+	var codes []string
+	seen := make(map[string]bool)
+
+	words := strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == ';' || r == '/' || r == '(' || r == ')' || r == ' ' || r == '\t' || r == '\n'
+	})
+	for _, word := range words {
+		code := strings.ToUpper(word)
+		if moduleCodePattern.MatchString(code) && !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+
+	return codes
+}
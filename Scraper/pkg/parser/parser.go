@@ -156,6 +156,8 @@ func parseMinorAndBDE(node *html.Node) ([]dto.Course, error) {
 			return resp, err
 		}
 
+		respCourse.PrerequisiteTree = ParsePrerequisite(respCourse.Prerequisite)
+		populateProgrammeCodes(&respCourse)
 		resp = append(resp, respCourse)
 	}
 
@@ -239,6 +241,9 @@ func parseNormalCourse(node *html.Node) (dto.Course, error) {
 		return resp, err
 	}
 
+	resp.PrerequisiteTree = ParsePrerequisite(resp.Prerequisite)
+	populateProgrammeCodes(&resp)
+
 	return resp, nil
 }
 
@@ -246,7 +251,7 @@ func ParseCourseSchedulesList(doc *html.Node) (*dto.CourseSchedules, error) {
 	courseSchedules := &dto.CourseSchedules{}
 
 	// Query for the "acadsem" select options
-	acadsemNodes, err := htmlquery.QueryAll(doc, `//select[@name="acadsem"]/option[not(contains(@value, "_S"))]`)
+	acadsemNodes, err := htmlquery.QueryAll(doc, `//select[@name="acadsem"]/option`)
 	if err != nil {
 		return nil, err
 	}
@@ -264,10 +269,12 @@ func ParseCourseSchedulesList(doc *html.Node) (*dto.CourseSchedules, error) {
 		return nil, err
 	}
 
+	courseSchedules.ProgrammeNames = make(map[string]string)
 	for _, node := range courseYearProgNodes {
 		courseYearProg := htmlquery.SelectAttr(node, "value")
 		if courseYearProg != "" {
 			courseSchedules.CourseYearProg = append(courseSchedules.CourseYearProg, courseYearProg)
+			courseSchedules.ProgrammeNames[courseYearProg] = strings.Join(strings.Fields(htmlquery.InnerText(node)), " ")
 		}
 	}
 
@@ -296,6 +303,13 @@ func ParseCourseModuleSchedules(doc *html.Node) []dto.Module {
 			Title: strings.Trim(htmlquery.InnerText(tds[1]), "*"),
 		}
 
+		// Some programmes mark whether the module is core or elective to them after the AU
+		for _, td := range tds[2:] {
+			if status := parseProgrammeStatus(htmlquery.InnerText(td)); status != "" {
+				module.Status = status
+			}
+		}
+
 		// Extract schedule details from the border table
 		borderRows, _ := htmlquery.QueryAll(borderTable, "./tbody/tr[position()>1]")
 		index := ""
@@ -369,6 +383,20 @@ func ParseCourseModuleSchedules(doc *html.Node) []dto.Module {
 
 	return modules
 }
+
+// parseProgrammeStatus maps the status of a module within a programme, e.g. "CORE" or "Prescribed Elective", to
+// PROGRAMME_CORE or PROGRAMME_ELECTIVE, it returns an empty string for anything else
+func parseProgrammeStatus(text string) string {
+	text = strings.ToUpper(strings.Join(strings.Fields(text), " "))
+	switch {
+	case text == "CORE" || strings.HasPrefix(text, "CORE "):
+		return dto.PROGRAMME_CORE
+	case strings.Contains(text, "ELECTIVE"):
+		return dto.PROGRAMME_ELECTIVE
+	}
+	return ""
+}
+
 func ParseExamSchedules(doc *html.Node) ([]dto.ExamSchedule, error) {
 	examSchedule := make([]dto.ExamSchedule, 0)
 
@@ -378,22 +406,28 @@ func ParseExamSchedules(doc *html.Node) ([]dto.ExamSchedule, error) {
 	}
 
 	// Iterate through each row of exam schedule
-	// Skips first row as it is the header of the table
-	if len(examNodes) > 1 {
+	// Skips first row as it is the header of the table, which is all there is when the course has no exam
+	if len(examNodes) > 0 {
 		examNodes = examNodes[1:]
 	}
 
 	for _, node := range examNodes {
 		scheduleNode := htmlquery.Find(node, "./td")
 
-		examSchedule = append(examSchedule, dto.ExamSchedule{
+		exam := dto.ExamSchedule{
 			Date:      strings.Join(strings.Fields(htmlquery.InnerText(scheduleNode[0])), " "),
 			DayOfWeek: strings.Join(strings.Fields(htmlquery.InnerText(scheduleNode[1])), " "),
 			Time:      strings.Join(strings.Fields(htmlquery.InnerText(scheduleNode[2])), " "),
 			Code:      strings.Join(strings.Fields(htmlquery.InnerText(scheduleNode[3])), " "),
 			Title:     strings.Join(strings.Fields(htmlquery.InnerText(scheduleNode[4])), " "),
 			Duration:  strings.Join(strings.Fields(htmlquery.InnerText(scheduleNode[5])), " "),
-		})
+		}
+
+		if err := populateExamTimes(&exam); err != nil {
+			fmt.Println("[ParseExamSchedules] Error parsing exam time for", exam.Code, ":", err)
+		}
+
+		examSchedule = append(examSchedule, exam)
 	}
 
 	return examSchedule, nil
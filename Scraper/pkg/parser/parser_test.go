@@ -0,0 +1,48 @@
+package parser
+
+import (
+	"github.com/antchfx/htmlquery"
+	"ntumods/pkg/dto"
+	"testing"
+)
+
+func TestParseCourseModuleSchedulesStatus(t *testing.T) {
+	doc, err := htmlquery.LoadDoc("../wistest/fixtures/schedule/ACC_GA_1_F.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statuses := map[string]string{}
+	for _, module := range ParseCourseModuleSchedules(doc) {
+		statuses[module.Code] = module.Status
+	}
+
+	expected := map[string]string{"AB1201": dto.PROGRAMME_CORE, "AC1103": "", "AC1104": dto.PROGRAMME_ELECTIVE}
+	for code, status := range expected {
+		if got, exists := statuses[code]; !exists || got != status {
+			t.Errorf("%s has status %q, expected %q", code, got, status)
+		}
+	}
+}
+
+func TestParseProgrammeStatus(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"CORE", dto.PROGRAMME_CORE},
+		{" Core ", dto.PROGRAMME_CORE},
+		{"CORE (Major)", dto.PROGRAMME_CORE},
+		{"Prescribed Elective", dto.PROGRAMME_ELECTIVE},
+		{"MAJOR  ELECTIVE", dto.PROGRAMME_ELECTIVE},
+		{"4.0 AU", ""},
+		{"CORES", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := parseProgrammeStatus(tt.text); got != tt.expected {
+			t.Errorf("parseProgrammeStatus(%q) is %q, expected %q", tt.text, got, tt.expected)
+		}
+	}
+}
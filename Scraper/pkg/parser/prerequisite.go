@@ -0,0 +1,263 @@
+package parser
+
+import (
+	"ntumods/pkg/dto"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	tokenModule = iota
+	tokenText
+	tokenAnd
+	tokenOr
+	tokenOpen
+	tokenClose
+)
+
+type prerequisiteToken struct {
+	kind  int
+	value string
+}
+
+var (
+	moduleCodePattern = regexp.MustCompile(`^[A-Z]{2,3}\d{3,4}[A-Z]?$`)
+	admissionPattern  = regexp.MustCompile(`^\(\s*(?i:admyr)\s*(\d{4})\s*(?:-\s*(?:(\d{4})|onwards))?\s*\)`)
+	standingPattern   = regexp.MustCompile(`(?i)^(?:study\s+)?year\s+(\d)\s+standing$`)
+	// "Grade B or better in H2 Mathematics", "Pass in H1 Mathematics"
+	gradeInPattern = regexp.MustCompile(`(?i)^(?:min(?:imum)?\s+)?(?:grade\s+)?([A-D][+-]?|pass)\s+(?:or\s+(?:better|above)\s+)?(?:in|for)(?:\s+(.+))?$`)
+	// "min grade C" written after a module code, e.g. "MH1810 (min grade C)"
+	minGradePattern = regexp.MustCompile(`(?i)^min(?:imum)?\s+grade\s+([A-D][+-]?)$`)
+	// "Corequisite" written after a module code, e.g. "AB1201(Corequisite)"
+	corequisitePattern = regexp.MustCompile(`(?i)^co-?requisite$`)
+)
+
+// ParsePrerequisite parses the free text prerequisite of a course into a boolean expression tree.
+// "&", "AND" and "," join requirements that must all be met, "OR" binds looser than them, and adjacent
+// requirements without an operator between them are joined as if by "&".
+// Clauses that are not module codes, year standings, admission years or grade conditions are kept as
+// PREREQ_TEXT nodes, while "(min grade C)" and "(Corequisite)" after a module code set the Grade and Corequisite of
+// its node. Returns nil when there is no prerequisite.
+func ParsePrerequisite(raw string) *dto.PrerequisiteNode {
+	tokens := tokenizePrerequisite(strings.Join(strings.Fields(raw), " "))
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	p := &prerequisiteParser{tokens: tokens}
+
+	var node *dto.PrerequisiteNode
+	for p.pos < len(p.tokens) {
+		// A stray closing bracket would otherwise stop parsing half way through
+		if p.peek() == tokenClose {
+			p.pos++
+			continue
+		}
+		node = joinPrerequisites(dto.PREREQ_AND, node, p.parseOr())
+	}
+
+	return node
+}
+
+func tokenizePrerequisite(raw string) []prerequisiteToken {
+	var tokens []prerequisiteToken
+	var text []string
+
+	flushText := func() {
+		if len(text) > 0 {
+			tokens = append(tokens, prerequisiteToken{kind: tokenText, value: strings.Join(text, " ")})
+			text = nil
+		}
+	}
+
+	for i := 0; i < len(raw); {
+		c := raw[i]
+		switch {
+		case c == ' ':
+			i++
+		case c == '(':
+			// "(Admyr 2021-onwards)" is a condition rather than a group
+			if m := admissionPattern.FindString(raw[i:]); m != "" {
+				flushText()
+				tokens = append(tokens, prerequisiteToken{kind: tokenText, value: m})
+				i += len(m)
+				continue
+			}
+			flushText()
+			tokens = append(tokens, prerequisiteToken{kind: tokenOpen})
+			i++
+		case c == ')':
+			flushText()
+			tokens = append(tokens, prerequisiteToken{kind: tokenClose})
+			i++
+		case c == '&' || c == ',' || c == ';':
+			flushText()
+			tokens = append(tokens, prerequisiteToken{kind: tokenAnd})
+			i++
+		default:
+			end := strings.IndexAny(raw[i:], " ()&,;")
+			if end == -1 {
+				end = len(raw) - i
+			}
+			word := raw[i : i+end]
+			i += end
+
+			switch {
+			case word == "OR":
+				flushText()
+				tokens = append(tokens, prerequisiteToken{kind: tokenOr})
+			case word == "AND":
+				flushText()
+				tokens = append(tokens, prerequisiteToken{kind: tokenAnd})
+			case moduleCodePattern.MatchString(word):
+				flushText()
+				tokens = append(tokens, prerequisiteToken{kind: tokenModule, value: word})
+			default:
+				text = append(text, word)
+			}
+		}
+	}
+	flushText()
+
+	return tokens
+}
+
+type prerequisiteParser struct {
+	tokens []prerequisiteToken
+	pos    int
+}
+
+func (p *prerequisiteParser) peek() int {
+	if p.pos >= len(p.tokens) {
+		return -1
+	}
+	return p.tokens[p.pos].kind
+}
+
+func (p *prerequisiteParser) parseOr() *dto.PrerequisiteNode {
+	node := p.parseAnd()
+	for p.peek() == tokenOr {
+		p.pos++
+		node = joinPrerequisites(dto.PREREQ_OR, node, p.parseAnd())
+	}
+	return node
+}
+
+func (p *prerequisiteParser) parseAnd() *dto.PrerequisiteNode {
+	node := p.parseOperand()
+	for {
+		switch p.peek() {
+		case tokenAnd:
+			p.pos++
+			node = joinPrerequisites(dto.PREREQ_AND, node, p.parseOperand())
+		case tokenModule, tokenText, tokenOpen:
+			node = p.attachOrJoin(node, p.parseOperand())
+		default:
+			return node
+		}
+	}
+}
+
+func (p *prerequisiteParser) parseOperand() *dto.PrerequisiteNode {
+	switch p.peek() {
+	case tokenOpen:
+		p.pos++
+		node := p.parseOr()
+		if p.peek() == tokenClose {
+			p.pos++
+		}
+		return node
+	case tokenModule:
+		token := p.tokens[p.pos]
+		p.pos++
+		return &dto.PrerequisiteNode{Type: dto.PREREQ_MODULE, Code: token.value}
+	case tokenText:
+		token := p.tokens[p.pos]
+		p.pos++
+
+		// "Grade B or better in AB1201" splits into a text token followed by the module
+		if m := gradeInPattern.FindStringSubmatch(token.value); m != nil && m[2] == "" && p.peek() == tokenModule {
+			node := p.parseOperand()
+			if !strings.EqualFold(m[1], "pass") {
+				node.Grade = strings.ToUpper(m[1])
+			}
+			return node
+		}
+		return classifyPrerequisiteText(token.value)
+	default:
+		// Operator without an operand, e.g. a trailing "OR"
+		if p.pos < len(p.tokens) && p.peek() != tokenClose {
+			p.pos++
+		}
+		return nil
+	}
+}
+
+// attachOrJoin folds "(min grade C)" and "(Corequisite)" into the module right before them, otherwise the operands
+// are joined with AND
+func (p *prerequisiteParser) attachOrJoin(left *dto.PrerequisiteNode, right *dto.PrerequisiteNode) *dto.PrerequisiteNode {
+	if left != nil && right != nil && right.Type == dto.PREREQ_TEXT {
+		// In "AB1201 & AB1202(Corequisite)" the module right before is the last one joined so far
+		module := left
+		if module.Type == dto.PREREQ_AND && len(module.Children) > 0 {
+			module = module.Children[len(module.Children)-1]
+		}
+
+		if module.Type == dto.PREREQ_MODULE {
+			if m := minGradePattern.FindStringSubmatch(right.Text); m != nil {
+				module.Grade = strings.ToUpper(m[1])
+				return left
+			}
+			if corequisitePattern.MatchString(right.Text) {
+				module.Corequisite = true
+				return left
+			}
+		}
+	}
+	return joinPrerequisites(dto.PREREQ_AND, left, right)
+}
+
+func classifyPrerequisiteText(text string) *dto.PrerequisiteNode {
+	if m := admissionPattern.FindStringSubmatch(text); m != nil {
+		node := &dto.PrerequisiteNode{Type: dto.PREREQ_ADMISSION, Text: text}
+		node.FromYear, _ = strconv.Atoi(m[1])
+		node.ToYear, _ = strconv.Atoi(m[2])
+		return node
+	}
+
+	if m := standingPattern.FindStringSubmatch(text); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		return &dto.PrerequisiteNode{Type: dto.PREREQ_STANDING, Year: year, Text: text}
+	}
+
+	if m := gradeInPattern.FindStringSubmatch(text); m != nil && m[2] != "" {
+		node := &dto.PrerequisiteNode{Type: dto.PREREQ_GRADE, Subject: m[2], Text: text}
+		if !strings.EqualFold(m[1], "pass") {
+			node.Grade = strings.ToUpper(m[1])
+		}
+		return node
+	}
+
+	return &dto.PrerequisiteNode{Type: dto.PREREQ_TEXT, Text: text}
+}
+
+// joinPrerequisites combines two nodes, flattening nested nodes of the same type
+func joinPrerequisites(nodeType string, left *dto.PrerequisiteNode, right *dto.PrerequisiteNode) *dto.PrerequisiteNode {
+	if left == nil {
+		return right
+	}
+	if right == nil {
+		return left
+	}
+
+	node := &dto.PrerequisiteNode{Type: nodeType}
+	for _, child := range []*dto.PrerequisiteNode{left, right} {
+		if child.Type == nodeType {
+			node.Children = append(node.Children, child.Children...)
+		} else {
+			node.Children = append(node.Children, child)
+		}
+	}
+	return node
+}
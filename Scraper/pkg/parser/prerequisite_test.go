@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"encoding/json"
+	"ntumods/pkg/dto"
+	"reflect"
+	"testing"
+)
+
+func module(code string) *dto.PrerequisiteNode {
+	return &dto.PrerequisiteNode{Type: dto.PREREQ_MODULE, Code: code}
+}
+
+func and(children ...*dto.PrerequisiteNode) *dto.PrerequisiteNode {
+	return &dto.PrerequisiteNode{Type: dto.PREREQ_AND, Children: children}
+}
+
+func or(children ...*dto.PrerequisiteNode) *dto.PrerequisiteNode {
+	return &dto.PrerequisiteNode{Type: dto.PREREQ_OR, Children: children}
+}
+
+func TestParsePrerequisite(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected *dto.PrerequisiteNode
+	}{
+		{"", nil},
+		{"AB1201", module("AB1201")},
+		{"AB1201 & AB1202", and(module("AB1201"), module("AB1202"))},
+		{"AB1201, AB1202 AND AB1203", and(module("AB1201"), module("AB1202"), module("AB1203"))},
+		{"AB1201 & AB1202 OR AB1203", or(and(module("AB1201"), module("AB1202")), module("AB1203"))},
+		{"AB1201 OR AB1202 & AB1203", or(module("AB1201"), and(module("AB1202"), module("AB1203")))},
+		{"(AB1201 OR AB1202) & (AB1203 OR AB1204)", and(or(module("AB1201"), module("AB1202")), or(module("AB1203"), module("AB1204")))},
+		{"AB1201 OR (AB1202 & (AB1203 OR AB1204))", or(module("AB1201"), and(module("AB1202"), or(module("AB1203"), module("AB1204"))))},
+		{"AC1103 OR\nAC1101", or(module("AC1103"), module("AC1101"))},
+		{"AB1201 OR", module("AB1201")},
+		{"AB1201 AB1202", and(module("AB1201"), module("AB1202"))},
+		{
+			"MH1810 (min grade C)",
+			&dto.PrerequisiteNode{Type: dto.PREREQ_MODULE, Code: "MH1810", Grade: "C"},
+		},
+		{
+			"Grade B or better in AB1201",
+			&dto.PrerequisiteNode{Type: dto.PREREQ_MODULE, Code: "AB1201", Grade: "B"},
+		},
+		{
+			"Grade B or better in H2 Mathematics",
+			&dto.PrerequisiteNode{Type: dto.PREREQ_GRADE, Subject: "H2 Mathematics", Grade: "B", Text: "Grade B or better in H2 Mathematics"},
+		},
+		{
+			"Pass in H1 Mathematics OR MH1100",
+			or(&dto.PrerequisiteNode{Type: dto.PREREQ_GRADE, Subject: "H1 Mathematics", Text: "Pass in H1 Mathematics"}, module("MH1100")),
+		},
+		{
+			"AB1201(Corequisite)",
+			&dto.PrerequisiteNode{Type: dto.PREREQ_MODULE, Code: "AB1201", Corequisite: true},
+		},
+		{
+			"AB1201 & AB1202 (Corequisite)",
+			and(module("AB1201"), &dto.PrerequisiteNode{Type: dto.PREREQ_MODULE, Code: "AB1202", Corequisite: true}),
+		},
+		{
+			"AB1201 OR AB1202(Co-requisite)",
+			or(module("AB1201"), &dto.PrerequisiteNode{Type: dto.PREREQ_MODULE, Code: "AB1202", Corequisite: true}),
+		},
+		{
+			"Year 2 standing",
+			&dto.PrerequisiteNode{Type: dto.PREREQ_STANDING, Year: 2, Text: "Year 2 standing"},
+		},
+		{
+			"(Admyr 2021-onwards) AB1201",
+			and(&dto.PrerequisiteNode{Type: dto.PREREQ_ADMISSION, FromYear: 2021, Text: "(Admyr 2021-onwards)"}, module("AB1201")),
+		},
+		{
+			"Approval by school",
+			&dto.PrerequisiteNode{Type: dto.PREREQ_TEXT, Text: "Approval by school"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			if got := ParsePrerequisite(tt.raw); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ParsePrerequisite(%q) is\n%s\nexpected\n%s", tt.raw, toJSON(got), toJSON(tt.expected))
+			}
+		})
+	}
+}
+
+func TestCorequisiteIsSatisfiedBy(t *testing.T) {
+	node := ParsePrerequisite("AB1201 & AB1202(Corequisite)")
+
+	tests := []struct {
+		name      string
+		student   dto.PrerequisiteStudent
+		satisfied bool
+	}{
+		{"both completed", dto.PrerequisiteStudent{Completed: map[string]string{"AB1201": "A", "AB1202": "B"}}, true},
+		{"corequisite taken alongside", dto.PrerequisiteStudent{Completed: map[string]string{"AB1201": "A"}, Taking: map[string]bool{"AB1202": true}}, true},
+		{"prerequisite taken alongside", dto.PrerequisiteStudent{Completed: map[string]string{"AB1202": "A"}, Taking: map[string]bool{"AB1201": true}}, false},
+		{"corequisite missing", dto.PrerequisiteStudent{Completed: map[string]string{"AB1201": "A"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := node.IsSatisfiedBy(tt.student); got != tt.satisfied {
+				t.Errorf("IsSatisfiedBy is %v, expected %v", got, tt.satisfied)
+			}
+		})
+	}
+}
+
+func toJSON(node *dto.PrerequisiteNode) string {
+	data, _ := json.MarshalIndent(node, "", "  ")
+	return string(data)
+}
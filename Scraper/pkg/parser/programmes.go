@@ -0,0 +1,49 @@
+package parser
+
+import (
+	"ntumods/pkg/dto"
+	"strings"
+)
+
+// ParseProgrammeCodes splits the programmes a module is restricted for, e.g. "BCE 1, BCG, DSAI", into a list.
+// The year of study and any qualifier in parentheses stay with their code, "CSC 2(Admyr 2021-onwards)" is a single
+// entry, and a qualifier on its own, e.g. "(Admyr 2011-2020)", applies to every programme.
+func ParseProgrammeCodes(raw string) []string {
+	var codes []string
+	var current strings.Builder
+	depth := 0
+
+	flush := func() {
+		// Rows continued onto the next line end in OR
+		code := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(current.String()), " OR"))
+		current.Reset()
+		if code != "" && code != "OR" {
+			codes = append(codes, strings.Join(strings.Fields(code), " "))
+		}
+	}
+
+	for _, r := range raw {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+		case r == ',' && depth == 0:
+			flush()
+			continue
+		}
+		current.WriteRune(r)
+	}
+	flush()
+
+	return codes
+}
+
+// populateProgrammeCodes fills the code lists of course from its raw restrictions
+func populateProgrammeCodes(course *dto.Course) {
+	course.NotAvailableToCodes = ParseProgrammeCodes(course.NotAvailableTo)
+	course.NotAvailableToProgWithCodes = ParseProgrammeCodes(course.NotAvailableToProgWith)
+	course.NotAvailableAsUECodes = ParseProgrammeCodes(course.NotAvailableAsUE)
+	course.NotAvailableAsPECodes = ParseProgrammeCodes(course.NotAvailableAsPE)
+	course.MutuallyExclusiveCodes = ParseMutuallyExclusive(course.MutuallyExclusive)
+}
@@ -0,0 +1,71 @@
+package planner
+
+import (
+	"ntumods/pkg/dto"
+	"sort"
+	"time"
+)
+
+type examSlot struct {
+	exam  dto.ExamSchedule
+	start time.Time
+	end   time.Time
+}
+
+// FindExamClashes reports every pair of exams that overlap, and every pair on the same day that does not
+func FindExamClashes(exams []dto.ExamSchedule) dto.ExamClashReport {
+	report := dto.ExamClashReport{
+		Clashes:    []dto.ExamClash{},
+		BackToBack: []dto.ExamClash{},
+		NoExam:     []string{},
+	}
+
+	var slots []examSlot
+	for _, exam := range exams {
+		start, errStart := time.Parse(time.RFC3339, exam.Start)
+		end, errEnd := time.Parse(time.RFC3339, exam.End)
+		if errStart != nil || errEnd != nil {
+			report.NoExam = append(report.NoExam, exam.Code)
+			continue
+		}
+		slots = append(slots, examSlot{exam: exam, start: start, end: end})
+	}
+
+	sort.Slice(slots, func(i, j int) bool { return slots[i].start.Before(slots[j].start) })
+
+	for i := 0; i < len(slots); i++ {
+		for j := i + 1; j < len(slots); j++ {
+			first, second := slots[i], slots[j]
+
+			if second.start.Before(first.end) {
+				overlapEnd := first.end
+				if second.end.Before(overlapEnd) {
+					overlapEnd = second.end
+				}
+
+				report.Clashes = append(report.Clashes, dto.ExamClash{
+					First:          first.exam,
+					Second:         second.exam,
+					OverlapMinutes: int(overlapEnd.Sub(second.start).Minutes()),
+				})
+				continue
+			}
+
+			if sameDay(first.start, second.start) {
+				report.BackToBack = append(report.BackToBack, dto.ExamClash{
+					First:      first.exam,
+					Second:     second.exam,
+					GapMinutes: int(second.start.Sub(first.end).Minutes()),
+				})
+			}
+		}
+	}
+
+	return report
+}
+
+func sameDay(a time.Time, b time.Time) bool {
+	a = a.In(dto.SINGAPORE)
+	b = b.In(dto.SINGAPORE)
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
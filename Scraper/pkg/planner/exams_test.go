@@ -0,0 +1,119 @@
+package planner
+
+import (
+	"ntumods/pkg/dto"
+	"reflect"
+	"testing"
+)
+
+func exam(code string, start string, end string) dto.ExamSchedule {
+	return dto.ExamSchedule{Code: code, Start: start, End: end}
+}
+
+// pairs lists each clash as "first/second"
+func pairs(clashes []dto.ExamClash) []string {
+	list := []string{}
+	for _, c := range clashes {
+		list = append(list, c.First.Code+"/"+c.Second.Code)
+	}
+	return list
+}
+
+func TestFindExamClashes(t *testing.T) {
+	tests := []struct {
+		name       string
+		exams      []dto.ExamSchedule
+		clashes    []string
+		backToBack []string
+		noExam     []string
+	}{
+		{
+			"overlap",
+			[]dto.ExamSchedule{
+				exam("AC1104", "2023-11-27T10:00:00+08:00", "2023-11-27T12:00:00+08:00"),
+				exam("AB1201", "2023-11-27T09:00:00+08:00", "2023-11-27T11:00:00+08:00"),
+			},
+			[]string{"AB1201/AC1104"}, []string{}, []string{},
+		},
+		{
+			"one exam within another",
+			[]dto.ExamSchedule{
+				exam("AB1201", "2023-11-27T09:00:00+08:00", "2023-11-27T12:00:00+08:00"),
+				exam("AC1104", "2023-11-27T10:00:00+08:00", "2023-11-27T11:00:00+08:00"),
+			},
+			[]string{"AB1201/AC1104"}, []string{}, []string{},
+		},
+		{
+			"back to back on the same day",
+			[]dto.ExamSchedule{
+				exam("AB1201", "2023-11-27T09:00:00+08:00", "2023-11-27T11:00:00+08:00"),
+				exam("AC1104", "2023-11-27T11:00:00+08:00", "2023-11-27T13:00:00+08:00"),
+				exam("AC2101", "2023-11-27T17:00:00+08:00", "2023-11-27T19:00:00+08:00"),
+			},
+			[]string{}, []string{"AB1201/AC1104", "AB1201/AC2101", "AC1104/AC2101"}, []string{},
+		},
+		{
+			"different days",
+			[]dto.ExamSchedule{
+				exam("AB1201", "2023-11-27T17:00:00+08:00", "2023-11-27T19:00:00+08:00"),
+				exam("AC1104", "2023-11-28T09:00:00+08:00", "2023-11-28T11:00:00+08:00"),
+			},
+			[]string{}, []string{}, []string{},
+		},
+		{
+			// 5pm on one day and 1am on the next in Singapore are on the same day in UTC
+			"different days in Singapore",
+			[]dto.ExamSchedule{
+				exam("AB1201", "2023-11-27T09:00:00Z", "2023-11-27T11:00:00Z"),
+				exam("AC1104", "2023-11-28T01:00:00+08:00", "2023-11-28T03:00:00+08:00"),
+			},
+			[]string{}, []string{}, []string{},
+		},
+		{
+			"unparseable times",
+			[]dto.ExamSchedule{
+				exam("AB1201", "2023-11-27T09:00:00+08:00", "2023-11-27T11:00:00+08:00"),
+				exam("AC1104", "", ""),
+				exam("AC2101", "27 November 2023", "2023-11-27T11:00:00+08:00"),
+				exam("HE9091", "2023-11-27T09:00:00+08:00", "11.00 am"),
+			},
+			[]string{}, []string{}, []string{"AC1104", "AC2101", "HE9091"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := FindExamClashes(tt.exams)
+
+			if got := pairs(report.Clashes); !reflect.DeepEqual(got, tt.clashes) {
+				t.Errorf("clashes are %v, expected %v", got, tt.clashes)
+			}
+			if got := pairs(report.BackToBack); !reflect.DeepEqual(got, tt.backToBack) {
+				t.Errorf("back to back are %v, expected %v", got, tt.backToBack)
+			}
+			if !reflect.DeepEqual(report.NoExam, tt.noExam) {
+				t.Errorf("no exam is %v, expected %v", report.NoExam, tt.noExam)
+			}
+		})
+	}
+}
+
+func TestFindExamClashesMinutes(t *testing.T) {
+	report := FindExamClashes([]dto.ExamSchedule{
+		exam("AB1201", "2023-11-27T09:00:00+08:00", "2023-11-27T11:00:00+08:00"),
+		exam("AC1104", "2023-11-27T10:30:00+08:00", "2023-11-27T12:30:00+08:00"),
+		exam("AC2101", "2023-11-27T14:00:00+08:00", "2023-11-27T16:00:00+08:00"),
+	})
+
+	if len(report.Clashes) != 1 || report.Clashes[0].OverlapMinutes != 30 {
+		t.Errorf("clashes are %+v, expected AB1201 and AC1104 to overlap by 30 minutes", report.Clashes)
+	}
+
+	gaps := map[string]int{}
+	for _, c := range report.BackToBack {
+		gaps[c.First.Code+"/"+c.Second.Code] = c.GapMinutes
+	}
+	if expected := map[string]int{"AB1201/AC2101": 180, "AC1104/AC2101": 90}; !reflect.DeepEqual(gaps, expected) {
+		t.Errorf("gaps are %v, expected %v", gaps, expected)
+	}
+}
@@ -0,0 +1,43 @@
+package planner
+
+import (
+	"ntumods/pkg/dto"
+	"sort"
+	"strings"
+)
+
+// FindExclusionConflicts reports every pair among codes that exclusions marks as mutually exclusive, sorted
+func FindExclusionConflicts(exclusions dto.ExclusionGraph, codes []string) []dto.ExclusionConflict {
+	selected := make([]string, 0, len(codes))
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		code = strings.ToUpper(code)
+		if !seen[code] {
+			seen[code] = true
+			selected = append(selected, code)
+		}
+	}
+	sort.Strings(selected)
+
+	conflicts := make([]dto.ExclusionConflict, 0)
+	for i, first := range selected {
+		for _, second := range selected[i+1:] {
+			if excludes(exclusions, first, second) {
+				conflicts = append(conflicts, dto.ExclusionConflict{First: first, Second: second})
+			}
+		}
+	}
+	return conflicts
+}
+
+// excludes checks both directions, so that a graph not built by graph.BuildExclusions still works
+func excludes(exclusions dto.ExclusionGraph, a string, b string) bool {
+	for _, pair := range [][2]string{{a, b}, {b, a}} {
+		for _, code := range exclusions.Modules[pair[0]] {
+			if code == pair[1] {
+				return true
+			}
+		}
+	}
+	return false
+}
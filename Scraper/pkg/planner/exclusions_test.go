@@ -0,0 +1,48 @@
+package planner
+
+import (
+	"ntumods/pkg/dto"
+	"reflect"
+	"testing"
+)
+
+func TestFindExclusionConflicts(t *testing.T) {
+	exclusions := dto.ExclusionGraph{
+		Modules: map[string][]string{
+			"AB1201": {"AC1103", "BF2201"},
+			"AC1103": {"AB1201"},
+			"AC1104": {},
+			// Declared by AC2101 only, as in a graph not built by graph.BuildExclusions
+			"AC2101": {"AC1104"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		codes    []string
+		expected []dto.ExclusionConflict
+	}{
+		{"none", []string{"AB1201", "AC1104"}, []dto.ExclusionConflict{}},
+		{"symmetric pair", []string{"AC1103", "AB1201"}, []dto.ExclusionConflict{{First: "AB1201", Second: "AC1103"}}},
+		{"one direction only", []string{"AC1104", "AC2101"}, []dto.ExclusionConflict{{First: "AC1104", Second: "AC2101"}}},
+		{"module not scraped", []string{"BF2201", "AB1201"}, []dto.ExclusionConflict{{First: "AB1201", Second: "BF2201"}}},
+		{"any case", []string{"ab1201", "Ac1103", "AB1201"}, []dto.ExclusionConflict{{First: "AB1201", Second: "AC1103"}}},
+		{
+			"several",
+			[]string{"AC2101", "ac1103", "ab1201", "AC1104", "bf2201"},
+			[]dto.ExclusionConflict{
+				{First: "AB1201", Second: "AC1103"},
+				{First: "AB1201", Second: "BF2201"},
+				{First: "AC1104", Second: "AC2101"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FindExclusionConflicts(exclusions, tt.codes); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("conflicts are %+v, expected %+v", got, tt.expected)
+			}
+		})
+	}
+}
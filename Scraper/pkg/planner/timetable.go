@@ -0,0 +1,295 @@
+package planner
+
+import (
+	"container/heap"
+	"fmt"
+	"ntumods/pkg/dto"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Classes starting before this time, in minutes from midnight, count as early
+const earlyClassCutoff = 9 * 60
+
+// TimetableOptions controls how SolveTimetable searches and ranks
+type TimetableOptions struct {
+	// RankBy lists RANK_* criteria, options are compared on the first and ties broken by the next
+	RankBy []string
+	// Limit caps the number of options returned, 0 returns every option found
+	Limit int
+	// MaxCombinations stops the search once this many clash-free options are found, 0 means
+	// dto.TIMETABLE_MAX_COMBINATIONS and a negative value searches every combination
+	MaxCombinations int
+}
+
+// rankedOption is a timetable option in the order it was found, which breaks ties between equally ranked options
+type rankedOption struct {
+	option dto.TimetableOption
+	seq    int
+}
+
+// rankedHeap keeps the worst ranked option at the top, so that it is the first to make room for a better one
+type rankedHeap struct {
+	options []rankedOption
+	rankBy  []string
+}
+
+func (h *rankedHeap) Len() int           { return len(h.options) }
+func (h *rankedHeap) Less(i, j int) bool { return ranksBefore(h.options[j], h.options[i], h.rankBy) }
+func (h *rankedHeap) Swap(i, j int)      { h.options[i], h.options[j] = h.options[j], h.options[i] }
+func (h *rankedHeap) Push(x interface{}) { h.options = append(h.options, x.(rankedOption)) }
+func (h *rankedHeap) Pop() interface{} {
+	last := h.options[len(h.options)-1]
+	h.options = h.options[:len(h.options)-1]
+	return last
+}
+
+// add keeps option if it ranks among the best limit options, every option when limit is 0
+func (h *rankedHeap) add(option rankedOption, limit int) {
+	if limit <= 0 || h.Len() < limit {
+		heap.Push(h, option)
+		return
+	}
+	if ranksBefore(option, h.options[0], h.rankBy) {
+		h.options[0] = option
+		heap.Fix(h, 0)
+	}
+}
+
+type session struct {
+	day   string
+	start int
+	end   int
+	weeks map[int]bool
+	venue string
+}
+
+type moduleIndexes struct {
+	code    string
+	indexes []string
+	// sessions of each index, in the same order as indexes
+	sessions [][]session
+}
+
+// SolveTimetable lists every choice of one index per module whose classes do not clash, two classes clash
+// when they are on the same day, overlap in time and share a teaching week.
+// Options are ranked as they are found, so the best Limit options are returned without keeping the others.
+// Modules without any class are left out of the options.
+func SolveTimetable(modules []dto.Combined, options TimetableOptions) (dto.TimetableResult, error) {
+	for _, criterion := range options.RankBy {
+		switch criterion {
+		case dto.RANK_FEWEST_DAYS, dto.RANK_NO_EARLY, dto.RANK_LEAST_GAPS:
+		default:
+			return dto.TimetableResult{}, fmt.Errorf("unknown ranking criterion: %s", criterion)
+		}
+	}
+
+	maxCombinations := options.MaxCombinations
+	if maxCombinations == 0 {
+		maxCombinations = dto.TIMETABLE_MAX_COMBINATIONS
+	}
+
+	var candidates []moduleIndexes
+	for _, m := range modules {
+		if mi := groupByIndex(m); len(mi.indexes) > 0 {
+			candidates = append(candidates, mi)
+		}
+	}
+
+	// Modules with the fewest indexes first prunes the search the earliest
+	sort.SliceStable(candidates, func(i, j int) bool { return len(candidates[i].indexes) < len(candidates[j].indexes) })
+
+	result := dto.TimetableResult{}
+	ranked := &rankedHeap{rankBy: options.RankBy}
+	chosen := make([]int, len(candidates))
+	var taken []session
+
+	var search func(depth int)
+	search = func(depth int) {
+		if result.Truncated {
+			return
+		}
+
+		if depth == len(candidates) {
+			if maxCombinations > 0 && result.Found == maxCombinations {
+				result.Truncated = true
+				return
+			}
+			ranked.add(rankedOption{option: scoreTimetable(candidates, chosen, taken), seq: result.Found}, options.Limit)
+			result.Found++
+			return
+		}
+
+		for i, sessions := range candidates[depth].sessions {
+			if clashesWithAny(sessions, taken) {
+				continue
+			}
+
+			chosen[depth] = i
+			taken = append(taken, sessions...)
+			search(depth + 1)
+			taken = taken[:len(taken)-len(sessions)]
+		}
+	}
+
+	if len(candidates) > 0 {
+		search(0)
+	}
+
+	sort.Slice(ranked.options, func(i, j int) bool { return ranksBefore(ranked.options[i], ranked.options[j], options.RankBy) })
+
+	result.Options = make([]dto.TimetableOption, 0, len(ranked.options))
+	for _, r := range ranked.options {
+		result.Options = append(result.Options, r.option)
+	}
+	return result, nil
+}
+
+// ranksBefore compares a and b on each criterion of rankBy in turn, the one found first wins a tie
+func ranksBefore(a rankedOption, b rankedOption, rankBy []string) bool {
+	for _, criterion := range rankBy {
+		x, y := rankValue(a.option, criterion), rankValue(b.option, criterion)
+		if x != y {
+			return x < y
+		}
+	}
+	return a.seq < b.seq
+}
+
+func groupByIndex(m dto.Combined) moduleIndexes {
+	mi := moduleIndexes{code: m.Code}
+	positions := make(map[string]int)
+
+	for _, s := range m.Schedule {
+		if s.Index == "" {
+			continue
+		}
+
+		pos, exists := positions[s.Index]
+		if !exists {
+			pos = len(mi.indexes)
+			positions[s.Index] = pos
+			mi.indexes = append(mi.indexes, s.Index)
+			mi.sessions = append(mi.sessions, nil)
+		}
+
+		start, errStart := parseClockMinutes(s.StartTime)
+		end, errEnd := parseClockMinutes(s.EndTime)
+		if errStart != nil || errEnd != nil || len(s.TeachingWeeks) == 0 {
+			// Classes without a time or outside teaching weeks cannot clash
+			continue
+		}
+
+		weeks := make(map[int]bool)
+		for _, w := range s.TeachingWeeks {
+			weeks[w] = true
+		}
+
+		mi.sessions[pos] = append(mi.sessions[pos], session{
+			day:   strings.ToUpper(s.DayOfWeek),
+			start: start,
+			end:   end,
+			weeks: weeks,
+			venue: s.Venue,
+		})
+	}
+
+	return mi
+}
+
+// parseClockMinutes parses a class time such as "0830" into minutes from midnight
+func parseClockMinutes(clock string) (int, error) {
+	clock = strings.TrimSpace(clock)
+	if len(clock) != 4 {
+		return 0, fmt.Errorf("unrecognised class time %q", clock)
+	}
+
+	hours, err := strconv.Atoi(clock[:2])
+	if err != nil {
+		return 0, err
+	}
+	minutes, err := strconv.Atoi(clock[2:])
+	if err != nil {
+		return 0, err
+	}
+
+	return hours*60 + minutes, nil
+}
+
+func clashesWithAny(sessions []session, taken []session) bool {
+	for _, a := range sessions {
+		for _, b := range taken {
+			if clashes(a, b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func clashes(a session, b session) bool {
+	if a.day != b.day || a.start >= b.end || b.start >= a.end {
+		return false
+	}
+
+	for w := range a.weeks {
+		if b.weeks[w] {
+			return true
+		}
+	}
+	return false
+}
+
+func scoreTimetable(candidates []moduleIndexes, chosen []int, taken []session) dto.TimetableOption {
+	option := dto.TimetableOption{Indexes: make(map[string]string)}
+	for i, c := range candidates {
+		option.Indexes[c.code] = c.indexes[chosen[i]]
+	}
+
+	byDay := make(map[string][]session)
+	for _, s := range taken {
+		byDay[s.day] = append(byDay[s.day], s)
+		if s.start < earlyClassCutoff {
+			option.EarlyClasses++
+		}
+	}
+
+	for _, sessions := range byDay {
+		onCampus := false
+		for _, s := range sessions {
+			if !strings.Contains(strings.ToUpper(s.venue), "ONLINE") {
+				onCampus = true
+			}
+		}
+		if onCampus {
+			option.CampusDays++
+		}
+
+		sort.Slice(sessions, func(i, j int) bool { return sessions[i].start < sessions[j].start })
+		latestEnd := sessions[0].end
+		for _, s := range sessions[1:] {
+			if s.start > latestEnd {
+				option.GapMinutes += s.start - latestEnd
+			}
+			if s.end > latestEnd {
+				latestEnd = s.end
+			}
+		}
+	}
+
+	return option
+}
+
+func rankValue(option dto.TimetableOption, criterion string) int {
+	switch criterion {
+	case dto.RANK_FEWEST_DAYS:
+		return option.CampusDays
+	case dto.RANK_NO_EARLY:
+		return option.EarlyClasses
+	case dto.RANK_LEAST_GAPS:
+		return option.GapMinutes
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,191 @@
+package planner
+
+import (
+	"ntumods/pkg/dto"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func class(index string, day string, start string, end string, weeks ...int) dto.Schedule {
+	if len(weeks) == 0 {
+		weeks = []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13}
+	}
+	return dto.Schedule{Index: index, DayOfWeek: day, StartTime: start, EndTime: end, TeachingWeeks: weeks, Venue: "LT1"}
+}
+
+func moduleWith(code string, classes ...dto.Schedule) dto.Combined {
+	m := dto.Combined{Schedule: classes}
+	m.Code = code
+	return m
+}
+
+// choices lists the indexes of each option as "AB1201=1,AC1103=3", sorted
+func choices(options []dto.TimetableOption) []string {
+	var list []string
+	for _, option := range options {
+		var pairs []string
+		for code, index := range option.Indexes {
+			pairs = append(pairs, code+"="+index)
+		}
+		sort.Strings(pairs)
+		list = append(list, strings.Join(pairs, ","))
+	}
+	sort.Strings(list)
+	return list
+}
+
+func TestSolveTimetableClashes(t *testing.T) {
+	modules := []dto.Combined{
+		moduleWith("AB1201",
+			class("1", "MON", "0830", "1030"),
+			class("2", "TUE", "1030", "1230"),
+		),
+		moduleWith("AC1103",
+			// Overlaps index 1 of AB1201
+			class("3", "MON", "0930", "1130"),
+			// Starts as index 1 of AB1201 ends, which is not a clash
+			class("4", "MON", "1030", "1130"),
+			// Overlaps index 2 of AB1201 but only in weeks it does not run
+			class("5", "TUE", "1100", "1200", 14),
+		),
+		// No classes, left out of the options
+		moduleWith("AC1104"),
+	}
+
+	result, err := SolveTimetable(modules, TimetableOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"AB1201=1,AC1103=4",
+		"AB1201=1,AC1103=5",
+		"AB1201=2,AC1103=3",
+		"AB1201=2,AC1103=4",
+		"AB1201=2,AC1103=5",
+	}
+	if got := choices(result.Options); !reflect.DeepEqual(got, expected) {
+		t.Errorf("options are %v, expected %v", got, expected)
+	}
+	if result.Found != len(expected) || result.Truncated {
+		t.Errorf("found %d, truncated %v, expected %d found and not truncated", result.Found, result.Truncated, len(expected))
+	}
+}
+
+func TestSolveTimetableClashWithinIndex(t *testing.T) {
+	// A lecture shared by every index clashing with the tutorial of another module leaves no option
+	modules := []dto.Combined{
+		moduleWith("AB1201", class("1", "MON", "0830", "1030"), class("1", "WED", "0830", "0930")),
+		moduleWith("AC1103", class("3", "WED", "0900", "1000")),
+	}
+
+	result, err := SolveTimetable(modules, TimetableOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Options) != 0 || result.Found != 0 {
+		t.Errorf("options are %v, expected none", choices(result.Options))
+	}
+}
+
+// rankingModules have a single best option for each criterion, found last by the search
+func rankingModules() []dto.Combined {
+	return []dto.Combined{
+		moduleWith("AB1201",
+			class("1", "MON", "0800", "0900"),
+			class("2", "TUE", "1000", "1100"),
+			class("3", "WED", "1000", "1100"),
+		),
+		moduleWith("AC1103",
+			class("4", "THU", "0800", "0900"),
+			class("5", "FRI", "1400", "1500"),
+			class("6", "WED", "1100", "1200"),
+		),
+	}
+}
+
+func TestSolveTimetableRanking(t *testing.T) {
+	tests := []struct {
+		name     string
+		rankBy   []string
+		expected []string
+	}{
+		// Only AB1201=3 and AC1103=6 share a day
+		{"fewest days", []string{dto.RANK_FEWEST_DAYS}, []string{"AB1201=3,AC1103=6"}},
+		// Both start at 10am or later, AB1201=2 was found before AB1201=3
+		{"no early classes", []string{dto.RANK_NO_EARLY}, []string{"AB1201=2,AC1103=5"}},
+		{"no early classes then least gaps", []string{dto.RANK_NO_EARLY, dto.RANK_LEAST_GAPS}, []string{"AB1201=2,AC1103=5"}},
+		{"least gaps then no early classes", []string{dto.RANK_LEAST_GAPS, dto.RANK_NO_EARLY}, []string{"AB1201=2,AC1103=5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := SolveTimetable(rankingModules(), TimetableOptions{RankBy: tt.rankBy, Limit: 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := choices(result.Options); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("best option is %v, expected %v", got, tt.expected)
+			}
+			if result.Found != 9 {
+				t.Errorf("found %d options, expected 9", result.Found)
+			}
+		})
+	}
+}
+
+func TestSolveTimetableLimitKeepsBestRanked(t *testing.T) {
+	rankBy := []string{dto.RANK_NO_EARLY, dto.RANK_FEWEST_DAYS, dto.RANK_LEAST_GAPS}
+
+	all, err := SolveTimetable(rankingModules(), TimetableOptions{RankBy: rankBy})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for limit := 1; limit <= len(all.Options); limit++ {
+		limited, err := SolveTimetable(rankingModules(), TimetableOptions{RankBy: rankBy, Limit: limit})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(limited.Options, all.Options[:limit]) {
+			t.Errorf("limit %d returned %+v, expected %+v", limit, limited.Options, all.Options[:limit])
+		}
+	}
+
+	for i := 1; i < len(all.Options); i++ {
+		if all.Options[i-1].EarlyClasses > all.Options[i].EarlyClasses {
+			t.Errorf("option %d has more early classes than option %d", i-1, i)
+		}
+	}
+}
+
+func TestSolveTimetableMaxCombinations(t *testing.T) {
+	tests := []struct {
+		max       int
+		found     int
+		truncated bool
+	}{
+		{4, 4, true},
+		{9, 9, false},
+		{-1, 9, false},
+		{0, 9, false},
+	}
+
+	for _, tt := range tests {
+		result, err := SolveTimetable(rankingModules(), TimetableOptions{MaxCombinations: tt.max})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Found != tt.found || result.Truncated != tt.truncated || len(result.Options) != tt.found {
+			t.Errorf("max %d found %d with %d options, truncated %v, expected %d found, truncated %v", tt.max, result.Found, len(result.Options), result.Truncated, tt.found, tt.truncated)
+		}
+	}
+}
+
+func TestSolveTimetableUnknownCriterion(t *testing.T) {
+	if _, err := SolveTimetable(rankingModules(), TimetableOptions{RankBy: []string{"most-days"}}); err == nil {
+		t.Error("expected an error for an unknown ranking criterion")
+	}
+}
@@ -0,0 +1,99 @@
+package programme
+
+import (
+	"ntumods/pkg/dto"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var unsafeKeyPattern = regexp.MustCompile(`[^A-Za-z0-9-]+`)
+
+// NewOffering describes courseYearProg, e.g. "ACC;GA;1;F", listed under name. The year is its third field.
+func NewOffering(courseYearProg string, name string, status string) dto.ProgrammeOffering {
+	offering := dto.ProgrammeOffering{Programme: courseYearProg, Name: name, Status: status}
+
+	fields := strings.Split(courseYearProg, ";")
+	if len(fields) > 2 {
+		if year, err := strconv.Atoi(strings.TrimSpace(fields[2])); err == nil {
+			offering.Year = year
+		}
+	}
+
+	if offering.Name == "" {
+		offering.Name = courseYearProg
+	}
+	return offering
+}
+
+// Key turns courseYearProg into a name usable in a storage key, "ACC;GA;1;F" becomes "ACC_GA_1_F"
+func Key(courseYearProg string) string {
+	return strings.Trim(unsafeKeyPattern.ReplaceAllString(courseYearProg, "_"), "_")
+}
+
+// Merge adds the offerings of extra whose programme is not in offerings, sorted by programme.
+// A status known from either side wins over an empty one.
+func Merge(offerings []dto.ProgrammeOffering, extra []dto.ProgrammeOffering) []dto.ProgrammeOffering {
+	byProgramme := make(map[string]dto.ProgrammeOffering)
+	for _, list := range [][]dto.ProgrammeOffering{offerings, extra} {
+		for _, o := range list {
+			if existing, exists := byProgramme[o.Programme]; exists {
+				if existing.Status == "" {
+					existing.Status = o.Status
+				}
+				byProgramme[o.Programme] = existing
+				continue
+			}
+			byProgramme[o.Programme] = o
+		}
+	}
+
+	merged := make([]dto.ProgrammeOffering, 0, len(byProgramme))
+	for _, o := range byProgramme {
+		merged = append(merged, o)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Programme < merged[j].Programme })
+	return merged
+}
+
+// Index groups modules by the programmes offering them, both sorted by programme and then by module code
+func Index(modules []dto.Combined) ([]dto.ProgrammeSummary, []dto.ProgrammeIndex) {
+	indexes := make(map[string]*dto.ProgrammeIndex)
+	for _, m := range modules {
+		for _, o := range m.Programmes {
+			index, exists := indexes[o.Programme]
+			if !exists {
+				index = &dto.ProgrammeIndex{Programme: o.Programme, Name: o.Name, Year: o.Year, Modules: []dto.ProgrammeModule{}}
+				indexes[o.Programme] = index
+			}
+
+			index.Modules = append(index.Modules, dto.ProgrammeModule{
+				Code:    m.Code,
+				Title:   m.Title,
+				AU:      m.AU,
+				Faculty: m.Faculty,
+				Status:  o.Status,
+			})
+		}
+	}
+
+	summaries := make([]dto.ProgrammeSummary, 0, len(indexes))
+	list := make([]dto.ProgrammeIndex, 0, len(indexes))
+	for _, index := range indexes {
+		sort.Slice(index.Modules, func(i, j int) bool { return index.Modules[i].Code < index.Modules[j].Code })
+		list = append(list, *index)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Programme < list[j].Programme })
+
+	for _, index := range list {
+		summaries = append(summaries, dto.ProgrammeSummary{
+			Programme: index.Programme,
+			Name:      index.Name,
+			Year:      index.Year,
+			Modules:   len(index.Modules),
+		})
+	}
+
+	return summaries, list
+}
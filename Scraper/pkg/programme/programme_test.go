@@ -0,0 +1,125 @@
+package programme
+
+import (
+	"ntumods/pkg/dto"
+	"reflect"
+	"testing"
+)
+
+func TestNewOffering(t *testing.T) {
+	tests := []struct {
+		courseYearProg string
+		name           string
+		status         string
+		expected       dto.ProgrammeOffering
+	}{
+		{"ACC;GA;1;F", "Accountancy Year 1 (GA)", dto.PROGRAMME_CORE,
+			dto.ProgrammeOffering{Programme: "ACC;GA;1;F", Name: "Accountancy Year 1 (GA)", Year: 1, Status: dto.PROGRAMME_CORE}},
+		// Without a name the programme is named after itself
+		{"ACC;GA;2;F", "", "", dto.ProgrammeOffering{Programme: "ACC;GA;2;F", Name: "ACC;GA;2;F", Year: 2}},
+		// Programmes without a year of study
+		{"CSC;;;F", "Computer Science", "", dto.ProgrammeOffering{Programme: "CSC;;;F", Name: "Computer Science"}},
+		{"MINOR", "Minor", "", dto.ProgrammeOffering{Programme: "MINOR", Name: "Minor"}},
+	}
+
+	for _, tt := range tests {
+		if got := NewOffering(tt.courseYearProg, tt.name, tt.status); got != tt.expected {
+			t.Errorf("NewOffering(%q, %q, %q) is %+v, expected %+v", tt.courseYearProg, tt.name, tt.status, got, tt.expected)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		courseYearProg string
+		expected       string
+	}{
+		{"ACC;GA;1;F", "ACC_GA_1_F"},
+		{"CSC;;1;F", "CSC_1_F"},
+		{"BUS/ACC 1 (Double)", "BUS_ACC_1_Double"},
+		{"MINOR-ART", "MINOR-ART"},
+	}
+
+	for _, tt := range tests {
+		if got := Key(tt.courseYearProg); got != tt.expected {
+			t.Errorf("Key(%q) is %q, expected %q", tt.courseYearProg, got, tt.expected)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	year1 := NewOffering("ACC;GA;1;F", "Accountancy Year 1 (GA)", "")
+	year1Core := NewOffering("ACC;GA;1;F", "Accountancy Year 1 (GA)", dto.PROGRAMME_CORE)
+	year1Elective := NewOffering("ACC;GA;1;F", "Accountancy Year 1 (GA)", dto.PROGRAMME_ELECTIVE)
+	year2 := NewOffering("ACC;GA;2;F", "Accountancy Year 2 (GA)", "")
+
+	tests := []struct {
+		name      string
+		offerings []dto.ProgrammeOffering
+		extra     []dto.ProgrammeOffering
+		expected  []dto.ProgrammeOffering
+	}{
+		{"added and sorted", []dto.ProgrammeOffering{year2}, []dto.ProgrammeOffering{year1}, []dto.ProgrammeOffering{year1, year2}},
+		{"duplicate", []dto.ProgrammeOffering{year1, year2}, []dto.ProgrammeOffering{year1}, []dto.ProgrammeOffering{year1, year2}},
+		// The content page does not show the status that the schedule page does
+		{"status from extra", []dto.ProgrammeOffering{year1}, []dto.ProgrammeOffering{year1Core}, []dto.ProgrammeOffering{year1Core}},
+		{"status kept", []dto.ProgrammeOffering{year1Core}, []dto.ProgrammeOffering{year1}, []dto.ProgrammeOffering{year1Core}},
+		{"first status wins", []dto.ProgrammeOffering{year1Core}, []dto.ProgrammeOffering{year1Elective}, []dto.ProgrammeOffering{year1Core}},
+		{"nothing", nil, nil, []dto.ProgrammeOffering{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Merge(tt.offerings, tt.extra); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Merge is %+v, expected %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIndex(t *testing.T) {
+	module := func(code string, offerings ...dto.ProgrammeOffering) dto.Combined {
+		var m dto.Combined
+		m.Code = code
+		m.Title = "TITLE OF " + code
+		m.AU = 4
+		m.Faculty = dto.Faculty{Code: "NBS"}
+		m.Programmes = offerings
+		return m
+	}
+
+	year1 := NewOffering("ACC;GA;1;F", "Accountancy Year 1 (GA)", "")
+	year2 := NewOffering("ACC;GA;2;F", "Accountancy Year 2 (GA)", "")
+	year1Core := NewOffering("ACC;GA;1;F", "Accountancy Year 1 (GA)", dto.PROGRAMME_CORE)
+
+	summaries, indexes := Index([]dto.Combined{
+		module("AC1104", year1, year2),
+		module("AB1201", year1Core),
+		module("HE9091"),
+	})
+
+	expectedSummaries := []dto.ProgrammeSummary{
+		{Programme: "ACC;GA;1;F", Name: "Accountancy Year 1 (GA)", Year: 1, Modules: 2},
+		{Programme: "ACC;GA;2;F", Name: "Accountancy Year 2 (GA)", Year: 2, Modules: 1},
+	}
+	if !reflect.DeepEqual(summaries, expectedSummaries) {
+		t.Errorf("summaries are %+v, expected %+v", summaries, expectedSummaries)
+	}
+
+	expectedIndexes := []dto.ProgrammeIndex{
+		{Programme: "ACC;GA;1;F", Name: "Accountancy Year 1 (GA)", Year: 1, Modules: []dto.ProgrammeModule{
+			{Code: "AB1201", Title: "TITLE OF AB1201", AU: 4, Faculty: dto.Faculty{Code: "NBS"}, Status: dto.PROGRAMME_CORE},
+			{Code: "AC1104", Title: "TITLE OF AC1104", AU: 4, Faculty: dto.Faculty{Code: "NBS"}},
+		}},
+		{Programme: "ACC;GA;2;F", Name: "Accountancy Year 2 (GA)", Year: 2, Modules: []dto.ProgrammeModule{
+			{Code: "AC1104", Title: "TITLE OF AC1104", AU: 4, Faculty: dto.Faculty{Code: "NBS"}},
+		}},
+	}
+	if !reflect.DeepEqual(indexes, expectedIndexes) {
+		t.Errorf("indexes are %+v, expected %+v", indexes, expectedIndexes)
+	}
+
+	if summaries, indexes = Index(nil); len(summaries) != 0 || len(indexes) != 0 {
+		t.Errorf("Index of no modules is %+v and %+v", summaries, indexes)
+	}
+}
@@ -9,20 +9,94 @@ import (
 	"net/url"
 	"ntumods/pkg/dto"
 	"ntumods/pkg/parser"
+	"os"
 	"reflect"
 	"strings"
 	"time"
 )
 
-func GetCourseSchedulePair() (*dto.CourseSchedules, error) {
-	currYear := time.Now().Year()
-	currMonth := time.Now().Month()
+// Config configures how a Scraper talks to NTU WIS, zero values fall back to the defaults in dto/constants.go
+type Config struct {
+	// Client is used for every request, its Timeout is overridden by Timeout when both are set
+	Client     *http.Client
+	BaseURL    string
+	Timeout    time.Duration
+	UserAgent  string
+	MaxRetries int
+	RetryDelay time.Duration
+}
+
+// Scraper fetches and parses pages from NTU WIS
+type Scraper struct {
+	client     *http.Client
+	baseURL    string
+	userAgent  string
+	maxRetries int
+	retryDelay time.Duration
+}
+
+// ConfigFromEnv reads the base URL, timeout and user agent from the environment
+func ConfigFromEnv() Config {
+	config := Config{
+		BaseURL:   os.Getenv("WIS_BASE_URL"),
+		UserAgent: os.Getenv("WIS_USER_AGENT"),
+	}
+
+	if timeout, err := time.ParseDuration(os.Getenv("WIS_TIMEOUT")); err == nil {
+		config.Timeout = timeout
+	}
+
+	return config
+}
 
-	var acadYearSem string
-	if currMonth < time.May {
-		acadYearSem = fmt.Sprintf("%d_2", currYear-1)
+func New(config Config) *Scraper {
+	client := config.Client
+	if client == nil {
+		client = &http.Client{}
 	} else {
-		acadYearSem = fmt.Sprintf("%d_1", currYear)
+		// Copy so that setting the timeout does not affect the caller's client
+		c := *client
+		client = &c
+	}
+
+	if config.Timeout > 0 {
+		client.Timeout = config.Timeout
+	} else if client.Timeout == 0 {
+		client.Timeout = dto.REQUEST_TIMEOUT
+	}
+
+	s := &Scraper{
+		client:     client,
+		baseURL:    strings.TrimSuffix(config.BaseURL, "/"),
+		userAgent:  config.UserAgent,
+		maxRetries: config.MaxRetries,
+		retryDelay: config.RetryDelay,
+	}
+
+	if s.baseURL == "" {
+		s.baseURL = dto.WIS_BASE_URL
+	}
+
+	if s.userAgent == "" {
+		s.userAgent = dto.USER_AGENT
+	}
+
+	if s.maxRetries <= 0 {
+		s.maxRetries = dto.MAX_RETRIES
+	}
+
+	if s.retryDelay <= 0 {
+		s.retryDelay = dto.RETRY_DELAY
+	}
+
+	return s
+}
+
+// GetCourseSchedulePair lists the semesters and the programmes offered in acadYearSem, an empty acadYearSem
+// means the current semester
+func (s *Scraper) GetCourseSchedulePair(acadYearSem string) (*dto.CourseSchedules, error) {
+	if acadYearSem == "" {
+		acadYearSem = CurrentSemester(time.Now())
 	}
 
 	request := &dto.CourseListRequestDto{
@@ -34,7 +108,7 @@ func GetCourseSchedulePair() (*dto.CourseSchedules, error) {
 		return nil, err
 	}
 
-	resp, err := postFormWithExponentialBackoff(dto.GET_INITIAL_COURSE_LIST, dto.CONTENT_OF_COURSES_INIT, *params)
+	resp, err := s.postWithExponentialBackoff(dto.GET_INITIAL_COURSE_LIST, dto.CONTENT_OF_COURSES_INIT, *params)
 	if err != nil {
 		return nil, err
 	}
@@ -48,13 +122,13 @@ func GetCourseSchedulePair() (*dto.CourseSchedules, error) {
 	return parser.ParseCourseSchedulesList(doc)
 }
 
-func GetContentOfCourses(request dto.CourseListRequestDto) ([]dto.Course, error) {
+func (s *Scraper) GetContentOfCourses(request dto.CourseListRequestDto) ([]dto.Course, error) {
 	params, err := constructRequiredCourseListFormData(request)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := postFormWithExponentialBackoff(dto.GET_COURSE_OFFERED_CONTENTS, dto.CONTENT_OF_COURSES, *params)
+	resp, err := s.postWithExponentialBackoff(dto.GET_COURSE_OFFERED_CONTENTS, dto.CONTENT_OF_COURSES, *params)
 	if err != nil {
 		return nil, err
 	}
@@ -69,13 +143,13 @@ func GetContentOfCourses(request dto.CourseListRequestDto) ([]dto.Course, error)
 	return parser.ParseCourses(doc)
 }
 
-func GetCourseSchedule(request dto.CourseScheduleRequestDto) ([]dto.Module, error) {
+func (s *Scraper) GetCourseSchedule(request dto.CourseScheduleRequestDto) ([]dto.Module, error) {
 	params, err := constructRequiredCourseScheduleFormData(request)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := postFormWithExponentialBackoff(dto.GET_CLASS_SCHEDULE, dto.CLASS_SCHEDULE, *params)
+	resp, err := s.postWithExponentialBackoff(dto.GET_CLASS_SCHEDULE, dto.CLASS_SCHEDULE, *params)
 	if err != nil {
 		return nil, err
 	}
@@ -89,13 +163,13 @@ func GetCourseSchedule(request dto.CourseScheduleRequestDto) ([]dto.Module, erro
 	return parser.ParseCourseModuleSchedules(doc), nil
 }
 
-func GetExamSchedule(request dto.CourseExamScheduleRequestDto) ([]dto.ExamSchedule, error) {
+func (s *Scraper) GetExamSchedule(request dto.CourseExamScheduleRequestDto) ([]dto.ExamSchedule, error) {
 	params, err := constructRequiredExamScheduleFormData(request)
 	if err != nil {
 		return nil, err
 	}
 
-	res, err := httpPostWithExponentialBackoff(dto.GET_EXAM_SCHEDULE, dto.EXAM_SCHEDULE, *params)
+	res, err := s.postWithExponentialBackoff(dto.GET_EXAM_SCHEDULE, dto.EXAM_SCHEDULE, *params)
 	if err != nil {
 		return nil, err
 	}
@@ -134,12 +208,12 @@ func constructRequiredCourseListFormData(params dto.CourseListRequestDto) (*url.
 }
 
 func constructRequiredCourseScheduleFormData(params dto.CourseScheduleRequestDto) (*url.Values, error) {
-	values := &url.Values{}
-	vVal := reflect.ValueOf(params)
-
 	// The current AcadYearSem uses 2023_1, but the required param here needs 2023;1
 	params.AcadYearSem = strings.Replace(params.AcadYearSem, "_", ";", 1)
 
+	values := &url.Values{}
+	vVal := reflect.ValueOf(params)
+
 	requestMap := map[string]string{
 		"AcadYearSem": "acadsem",
 		"FilterParam": "r_course_yr",
@@ -190,45 +264,33 @@ func constructRequiredExamScheduleFormData(params dto.CourseExamScheduleRequestD
 	return values, nil
 }
 
-func postFormWithExponentialBackoff(service string, url string, data url.Values) (*http.Response, error) {
+func (s *Scraper) postWithExponentialBackoff(service string, path string, data url.Values) (*http.Response, error) {
 	var resp *http.Response
 	var err error
-	for attempt := 0; attempt < dto.MAX_RETRIES; attempt++ {
-		resp, err = http.PostForm(url, data)
-		if err == nil {
-			return resp, nil
-		}
-
-		// Calculate the delay with exponential backoff and some randomness
-		delay := dto.RETRY_DELAY*time.Duration(math.Pow(2, float64(attempt))) + time.Duration(rand.Intn(int(dto.RETRY_DELAY)))
-		fmt.Printf("failed to fetch data for [%d], will retry in [%d] seconds\n", service, delay)
-		time.Sleep(delay)
-	}
-	return nil, fmt.Errorf("after %d attempts, last error: %s", dto.MAX_RETRIES, err)
-}
-
-func httpPostWithExponentialBackoff(service string, url string, data url.Values) (*http.Response, error) {
-	client := &http.Client{}
-
-	var resp *http.Response
-	var err error
-	for attempt := 0; attempt < dto.MAX_RETRIES; attempt++ {
-		payload := strings.NewReader(data.Encode())
-		req, err := http.NewRequest("POST", url, payload)
+	for attempt := 0; attempt < s.maxRetries; attempt++ {
+		var req *http.Request
+		req, err = http.NewRequest(http.MethodPost, s.baseURL+path, strings.NewReader(data.Encode()))
 		if err != nil {
 			return nil, err
 		}
-		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("User-Agent", s.userAgent)
 
-		resp, err = client.Do(req)
-		if err == nil {
+		resp, err = s.client.Do(req)
+		if err == nil && resp.StatusCode == http.StatusOK {
 			return resp, nil
 		}
 
+		// An error page of WIS would otherwise parse as a programme without any module
+		if err == nil {
+			resp.Body.Close()
+			err = fmt.Errorf("status %s", resp.Status)
+		}
+
 		// Calculate the delay with exponential backoff and some randomness
-		delay := dto.RETRY_DELAY*time.Duration(math.Pow(2, float64(attempt))) + time.Duration(rand.Intn(int(dto.RETRY_DELAY)))
-		fmt.Printf("failed to fetch data for [%d], will retry in [%d] seconds\n", service, delay)
+		delay := s.retryDelay*time.Duration(math.Pow(2, float64(attempt))) + time.Duration(rand.Intn(int(s.retryDelay)))
+		fmt.Printf("failed to fetch data for [%s], will retry in [%s]\n", service, delay)
 		time.Sleep(delay)
 	}
-	return nil, fmt.Errorf("after %d attempts, last error: %s", dto.MAX_RETRIES, err)
+	return nil, fmt.Errorf("after %d attempts, last error: %s", s.maxRetries, err)
 }
@@ -0,0 +1,145 @@
+package scraper
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"ntumods/pkg/cassette"
+	"ntumods/pkg/dto"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// cassetteDir holds interactions recorded from the real NTU WIS, record them from Scraper/cmd with
+//
+//	WIS_CASSETTE_MODE=record WIS_CASSETTE_DIR=../pkg/scraper/testdata/cassette STORAGE_BACKEND=memory \
+//	    go run . scrape -semester 2023_1 -scope "ACC;GA;1"
+//
+// Interactions are keyed by the semester in their form, so they keep replaying after the calendar moves on.
+const cassetteDir = "testdata/cassette"
+
+// recordedInteractions returns the interactions recorded from endpoint in cassetteDir
+func recordedInteractions(t *testing.T, endpoint string) []cassette.Interaction {
+	names, _ := filepath.Glob(filepath.Join(cassetteDir, path.Base(endpoint)+"-*.json"))
+	if len(names) == 0 {
+		t.Skip("no", path.Base(endpoint), "recorded from NTU WIS in", cassetteDir)
+	}
+
+	var interactions []cassette.Interaction
+	for _, name := range names {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var interaction cassette.Interaction
+		if err = json.Unmarshal(data, &interaction); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		interactions = append(interactions, interaction)
+	}
+	return interactions
+}
+
+// replayScraper returns a Scraper that replays cassetteDir against the host interaction was recorded from
+func replayScraper(t *testing.T, interaction cassette.Interaction) *Scraper {
+	recorded, err := url.Parse(interaction.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &http.Client{Transport: cassette.NewReplayer(cassetteDir)}
+	return New(Config{Client: client, BaseURL: recorded.Scheme + "://" + recorded.Host, MaxRetries: 1})
+}
+
+func TestConstructRequiredCourseScheduleFormData(t *testing.T) {
+	values, err := constructRequiredCourseScheduleFormData(dto.CourseScheduleRequestDto{
+		AcadYearSem: "2023_1",
+		FilterParam: "ACC;GA;1;F",
+		BOption:     "CLoad",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"acadsem":       "2023;1",
+		"r_course_yr":   "ACC;GA;1;F",
+		"r_subj_code":   "",
+		"boption":       "CLoad",
+		"r_search_type": "",
+		"staff_access":  "",
+	}
+	for field, value := range expected {
+		if got := (*values)[field]; len(got) != 1 || got[0] != value {
+			t.Errorf("%s is %v, expected [%s]", field, got, value)
+		}
+	}
+	if len(*values) != len(expected) {
+		t.Errorf("form has %d fields, expected %d: %v", len(*values), len(expected), values.Encode())
+	}
+}
+
+// TestGetCourseScheduleCassette replays every class schedule request recorded from the real NTU WIS, checking that
+// the scraper still sends the same form and that WIS answered it with classes
+func TestGetCourseScheduleCassette(t *testing.T) {
+	for _, interaction := range recordedInteractions(t, dto.CLASS_SCHEDULE) {
+		wis := replayScraper(t, interaction)
+
+		t.Run(interaction.Form.Get("acadsem")+" "+interaction.Form.Get("r_course_yr"), func(t *testing.T) {
+			modules, err := wis.GetCourseSchedule(dto.CourseScheduleRequestDto{
+				AcadYearSem: strings.Replace(interaction.Form.Get("acadsem"), ";", "_", 1),
+				FilterParam: interaction.Form.Get("r_course_yr"),
+				BOption:     interaction.Form.Get("boption"),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(modules) == 0 {
+				t.Error("NTU WIS returned no modules for the recorded form")
+			}
+		})
+	}
+}
+
+// TestGetCourseSchedulePairCassette replays the semester and programme lists recorded from the real NTU WIS. The
+// semester is the one recorded rather than the current one, which an empty semester would ask for.
+func TestGetCourseSchedulePairCassette(t *testing.T) {
+	for _, interaction := range recordedInteractions(t, dto.CONTENT_OF_COURSES_INIT) {
+		wis := replayScraper(t, interaction)
+		semester := interaction.Form.Get("acadsem")
+
+		t.Run(semester, func(t *testing.T) {
+			pair, err := wis.GetCourseSchedulePair(semester)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pair.AcadYearSem) == 0 || len(pair.CourseYearProg) == 0 {
+				t.Errorf("NTU WIS listed %d semesters and %d programmes", len(pair.AcadYearSem), len(pair.CourseYearProg))
+			}
+		})
+	}
+}
+
+func TestGetCourseScheduleErrorStatus(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		http.Error(w, "<html><body>Service Unavailable</body></html>", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	wis := New(Config{BaseURL: server.URL, MaxRetries: 2, RetryDelay: time.Millisecond})
+	modules, err := wis.GetCourseSchedule(dto.CourseScheduleRequestDto{AcadYearSem: "2023_1", FilterParam: "ACC;GA;1;F", BOption: "CLoad"})
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("GetCourseSchedule returned %d modules and error %v, expected a 503 error", len(modules), err)
+	}
+	if attempts != 2 {
+		t.Errorf("%d attempts were made, expected 2", attempts)
+	}
+}
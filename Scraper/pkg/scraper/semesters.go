@@ -0,0 +1,93 @@
+package scraper
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var acadYearSemPattern = regexp.MustCompile(`^\d{4}_\w$`)
+
+// CurrentSemester returns the regular semester in session at now, e.g. 2023_1 from May to December 2023
+func CurrentSemester(now time.Time) string {
+	if now.Month() < time.May {
+		return fmt.Sprintf("%d_2", now.Year()-1)
+	}
+	return fmt.Sprintf("%d_1", now.Year())
+}
+
+// IsSpecialTerm reports whether acadYearSem, e.g. 2023_S, is a special term rather than semester 1 or 2
+func IsSpecialTerm(acadYearSem string) bool {
+	parts := strings.SplitN(acadYearSem, "_", 2)
+	return len(parts) == 2 && parts[1] != "1" && parts[1] != "2"
+}
+
+// ResolveSemesters turns a comma separated selection into the semesters to scrape, listed being the semesters
+// offered by WIS in order. Each entry is either a semester such as 2022_2 or 2023_S, "latest" for the last
+// listed regular semester, "all" for every listed semester, or a range such as 2021_1..2023_1 over the listed
+// semesters. An empty selection means "latest".
+func ResolveSemesters(selection string, listed []string) ([]string, error) {
+	if strings.TrimSpace(selection) == "" {
+		selection = "latest"
+	}
+
+	var semesters []string
+	seen := make(map[string]bool)
+	add := func(semester string) {
+		if !seen[semester] {
+			seen[semester] = true
+			semesters = append(semesters, semester)
+		}
+	}
+
+	for _, entry := range strings.Split(selection, ",") {
+		entry = strings.TrimSpace(entry)
+
+		switch {
+		case entry == "latest":
+			latest := ""
+			for _, semester := range listed {
+				if !IsSpecialTerm(semester) {
+					latest = semester
+				}
+			}
+			if latest == "" {
+				return nil, fmt.Errorf("no regular semester is listed")
+			}
+			add(latest)
+		case entry == "all":
+			for _, semester := range listed {
+				add(semester)
+			}
+		case strings.Contains(entry, ".."):
+			bounds := strings.SplitN(entry, "..", 2)
+			from, to := indexOf(listed, bounds[0]), indexOf(listed, bounds[1])
+			if from < 0 || to < 0 {
+				return nil, fmt.Errorf("range %s must start and end at a listed semester", entry)
+			}
+			if from > to {
+				from, to = to, from
+			}
+			for _, semester := range listed[from : to+1] {
+				add(semester)
+			}
+		case acadYearSemPattern.MatchString(entry):
+			// Past semesters drop off the list on WIS but may still be served, so they are not checked against it
+			add(entry)
+		default:
+			return nil, fmt.Errorf("unrecognised semester %q, expected e.g. 2023_1, latest, all or 2021_1..2023_1", entry)
+		}
+	}
+
+	return semesters, nil
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
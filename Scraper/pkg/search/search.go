@@ -0,0 +1,181 @@
+package search
+
+import (
+	"math"
+	"ntumods/pkg/dto"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Weight of a term found in each field of a module
+const (
+	codeWeight        = 10
+	titleWeight       = 3
+	descriptionWeight = 1
+)
+
+// Shortest term a partly typed word is matched against when it has run past it
+const minCompletionLength = 4
+
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true, "for": true,
+	"from": true, "in": true, "is": true, "it": true, "of": true, "on": true, "or": true, "that": true, "the": true,
+	"this": true, "to": true, "with": true, "will": true, "which": true, "their": true, "these": true, "its": true,
+}
+
+// Index searches a dto.SearchIndex
+type Index struct {
+	index dto.SearchIndex
+	// terms lists every term of the index in order, for prefix search
+	terms []string
+}
+
+// Tokenize splits text into lowercase words, drops stop words and stems the rest
+func Tokenize(text string) []string {
+	tokens := words(text)
+	for n, word := range tokens {
+		tokens[n] = Stem(word)
+	}
+	return tokens
+}
+
+// words splits text into lowercase words without stop words
+func words(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	kept := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if !stopWords[field] {
+			kept = append(kept, field)
+		}
+	}
+	return kept
+}
+
+// Build indexes the code, title and description of courses
+func Build(courses []dto.Course) dto.SearchIndex {
+	sorted := append([]dto.Course(nil), courses...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Code < sorted[j].Code })
+
+	index := dto.SearchIndex{
+		Modules: make([]string, 0, len(sorted)),
+		Titles:  make([]string, 0, len(sorted)),
+		Terms:   make(map[string][]dto.SearchPosting),
+	}
+
+	for i, c := range sorted {
+		index.Modules = append(index.Modules, c.Code)
+		index.Titles = append(index.Titles, c.Title)
+
+		weights := make(map[string]int)
+		for _, token := range Tokenize(c.Code) {
+			weights[token] += codeWeight
+		}
+		for _, token := range Tokenize(c.Title) {
+			weights[token] += titleWeight
+		}
+		for _, token := range Tokenize(c.Description) {
+			weights[token] += descriptionWeight
+		}
+
+		for term, weight := range weights {
+			index.Terms[term] = append(index.Terms[term], dto.SearchPosting{Module: i, Weight: weight})
+		}
+	}
+
+	return index
+}
+
+func NewIndex(index dto.SearchIndex) *Index {
+	terms := make([]string, 0, len(index.Terms))
+	for term := range index.Terms {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+
+	return &Index{index: index, terms: terms}
+}
+
+// Search returns the modules containing every word of query, best match first. The last word may be partly
+// typed, so that results can be shown as the query is typed. A limit of 0 returns every match.
+func (i *Index) Search(query string, limit int) []dto.SearchResult {
+	queryWords := words(query)
+
+	results := []dto.SearchResult{}
+	if len(queryWords) == 0 {
+		return results
+	}
+
+	var scores map[int]float64
+	for n, word := range queryWords {
+		terms := []string{Stem(word)}
+		if n == len(queryWords)-1 {
+			terms = i.completions(word)
+		}
+
+		matched := make(map[int]float64)
+		for _, term := range terms {
+			postings := i.index.Terms[term]
+			idf := math.Log(1 + float64(len(i.index.Modules))/float64(len(postings)))
+			for _, posting := range postings {
+				matched[posting.Module] += float64(posting.Weight) * idf
+			}
+		}
+
+		// Every word of the query has to match
+		if scores == nil {
+			scores = matched
+			continue
+		}
+		for module, score := range scores {
+			if extra, exists := matched[module]; exists {
+				scores[module] = score + extra
+			} else {
+				delete(scores, module)
+			}
+		}
+	}
+
+	for module, score := range scores {
+		results = append(results, dto.SearchResult{
+			Code:  i.index.Modules[module],
+			Title: i.index.Titles[module],
+			Score: math.Round(score*1000) / 1000,
+		})
+	}
+
+	sort.Slice(results, func(a, b int) bool {
+		if results[a].Score != results[b].Score {
+			return results[a].Score > results[b].Score
+		}
+		return results[a].Code < results[b].Code
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// completions returns the terms a partly typed word may grow into. These are the terms starting with its stem, and
+// the terms it already runs past, e.g. "busin" is past "busi" that "business" is indexed under.
+func (i *Index) completions(word string) []string {
+	stem := Stem(word)
+
+	start := sort.SearchStrings(i.terms, stem)
+	end := start
+	for end < len(i.terms) && strings.HasPrefix(i.terms[end], stem) {
+		end++
+	}
+	terms := append([]string(nil), i.terms[start:end]...)
+
+	for n := minCompletionLength; n < len(word); n++ {
+		if _, exists := i.index.Terms[word[:n]]; exists && !strings.HasPrefix(word[:n], stem) {
+			terms = append(terms, word[:n])
+		}
+	}
+	return terms
+}
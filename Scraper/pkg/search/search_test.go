@@ -0,0 +1,58 @@
+package search
+
+import (
+	"ntumods/pkg/dto"
+	"reflect"
+	"testing"
+)
+
+func testIndex() *Index {
+	return NewIndex(Build([]dto.Course{
+		{Code: "AB1201", Title: "FINANCIAL MANAGEMENT", Description: "Financial statements and the management of working capital, with cases in accounting."},
+		{Code: "AC1103", Title: "INTRODUCTION TO FINANCIAL ACCOUNTING", Description: "How accountants record transactions."},
+		{Code: "AC1104", Title: "FINANCIAL ACCOUNTING II", Description: "Financial accounting of groups."},
+		{Code: "AC2101", Title: "ACCOUNTING INFORMATION SYSTEMS", Description: "Information systems used by accountants."},
+		{Code: "CZ1003", Title: "INTRODUCTION TO COMPUTATIONAL THINKING", Description: "Programming in Python."},
+	}))
+}
+
+func codes(results []dto.SearchResult) []string {
+	list := []string{}
+	for _, result := range results {
+		list = append(list, result.Code)
+	}
+	return list
+}
+
+func TestSearchRanking(t *testing.T) {
+	tests := []struct {
+		query    string
+		limit    int
+		expected []string
+	}{
+		// Modules with the word in their title rank above AB1201 with it in the description only, AC1103 and
+		// AC1104 also have it in the description and tie, ordered by code
+		{"accounting", 0, []string{"AC1103", "AC1104", "AC2101", "AB1201"}},
+		{"accountants", 0, []string{"AC1103", "AC1104", "AC2101", "AB1201"}},
+		{"accounting", 2, []string{"AC1103", "AC1104"}},
+		// Every word has to match
+		{"financial accounting", 0, []string{"AC1104", "AC1103", "AB1201"}},
+		{"management accounting", 0, []string{"AB1201"}},
+		// A code outranks any title
+		{"ab1201", 0, []string{"AB1201"}},
+		// The last word may be partly typed
+		{"introduction comp", 0, []string{"CZ1003"}},
+		{"informat", 0, []string{"AC2101"}},
+		{"the", 0, []string{}},
+		{"biology", 0, []string{}},
+	}
+
+	index := testIndex()
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := codes(index.Search(tt.query, tt.limit)); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Search(%q) returned %v, expected %v", tt.query, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package search
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStem(t *testing.T) {
+	tests := []struct {
+		word     string
+		expected string
+	}{
+		// The examples of each step in M.F. Porter, An algorithm for suffix stripping, 1980
+		{"caresses", "caress"}, {"ponies", "poni"}, {"ties", "ti"}, {"caress", "caress"}, {"cats", "cat"},
+		{"feed", "feed"}, {"agreed", "agre"}, {"plastered", "plaster"}, {"bled", "bled"}, {"motoring", "motor"},
+		{"sing", "sing"}, {"conflated", "conflat"}, {"troubled", "troubl"}, {"sized", "size"}, {"hopping", "hop"},
+		{"tanned", "tan"}, {"falling", "fall"}, {"hissing", "hiss"}, {"fizzed", "fizz"}, {"failing", "fail"},
+		{"filing", "file"}, {"happy", "happi"}, {"sky", "sky"},
+		{"relational", "relat"}, {"conditional", "condit"}, {"rational", "ration"}, {"valenci", "valenc"},
+		{"hesitanci", "hesit"}, {"digitizer", "digit"}, {"conformabli", "conform"}, {"radicalli", "radic"},
+		{"differentli", "differ"}, {"vileli", "vile"}, {"analogousli", "analog"}, {"vietnamization", "vietnam"},
+		{"predication", "predic"}, {"operator", "oper"}, {"feudalism", "feudal"}, {"decisiveness", "decis"},
+		{"hopefulness", "hope"}, {"callousness", "callous"}, {"formaliti", "formal"}, {"sensitiviti", "sensit"},
+		{"sensibiliti", "sensibl"},
+		{"triplicate", "triplic"}, {"formative", "form"}, {"formalize", "formal"}, {"electriciti", "electr"},
+		{"electrical", "electr"}, {"hopeful", "hope"}, {"goodness", "good"},
+		{"revival", "reviv"}, {"allowance", "allow"}, {"inference", "infer"}, {"airliner", "airlin"},
+		{"gyroscopic", "gyroscop"}, {"adjustable", "adjust"}, {"defensible", "defens"}, {"irritant", "irrit"},
+		{"replacement", "replac"}, {"adjustment", "adjust"}, {"dependent", "depend"}, {"adoption", "adopt"},
+		{"homologou", "homolog"}, {"communism", "commun"}, {"activate", "activ"}, {"angulariti", "angular"},
+		{"homologous", "homolog"}, {"effective", "effect"}, {"bowdlerize", "bowdler"},
+		{"probate", "probat"}, {"rate", "rate"}, {"cease", "ceas"}, {"controll", "control"}, {"roll", "roll"},
+		{"generalizations", "gener"}, {"oscillators", "oscil"},
+		// Where the reference implementation departs from the paper
+		{"possibly", "possibl"}, {"terminology", "terminolog"},
+		// Words of module titles
+		{"accounting", "account"}, {"accountants", "account"}, {"financial", "financi"}, {"management", "manag"},
+		{"engineering", "engin"}, {"mathematics", "mathemat"}, {"programming", "program"},
+		{"statistics", "statist"},
+		// Left as they are
+		{"is", "is"}, {"h2", "h2"}, {"1201", "1201"},
+	}
+
+	for _, tt := range tests {
+		if got := Stem(tt.word); got != tt.expected {
+			t.Errorf("Stem(%q) is %q, expected %q", tt.word, got, tt.expected)
+		}
+	}
+}
+
+// TestStemVocabulary stems the vocabulary published with the reference implementation of the Porter stemmer,
+// https://tartarus.org/martin/PorterStemmer/voc.txt and output.txt, when they are saved to testdata
+func TestStemVocabulary(t *testing.T) {
+	vocabulary, err := os.Open(filepath.Join("testdata", "voc.txt"))
+	if os.IsNotExist(err) {
+		t.Skip("testdata/voc.txt is missing, download it from https://tartarus.org/martin/PorterStemmer/voc.txt")
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	defer vocabulary.Close()
+
+	output, err := os.Open(filepath.Join("testdata", "output.txt"))
+	if os.IsNotExist(err) {
+		t.Skip("testdata/output.txt is missing, download it from https://tartarus.org/martin/PorterStemmer/output.txt")
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	defer output.Close()
+
+	words := bufio.NewScanner(vocabulary)
+	stems := bufio.NewScanner(output)
+	for words.Scan() {
+		if !stems.Scan() {
+			t.Fatalf("output.txt ends before %q", words.Text())
+		}
+		if got := Stem(words.Text()); got != stems.Text() {
+			t.Errorf("Stem(%q) is %q, expected %q", words.Text(), got, stems.Text())
+		}
+	}
+	if err = words.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"path"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+)
+
+// AzureBlobStorage stores each key as a block blob within a single container
+type AzureBlobStorage struct {
+	containerURL azblob.ContainerURL
+}
+
+func NewAzureBlobStorage(accountName string, accountKey string, containerName string) (*AzureBlobStorage, error) {
+	credential, err := azblob.NewSharedKeyCredential(accountName, accountKey)
+	if err != nil {
+		return nil, fmt.Errorf("[NewAzureBlobStorage] Failed to create credential: %v", err)
+	}
+
+	// Create a container URL that points to the container within the storage account.
+	containerURL := fmt.Sprintf("https://%s.blob.core.windows.net/%s", accountName, containerName)
+	u, err := url.Parse(containerURL)
+	if err != nil {
+		return nil, fmt.Errorf("[NewAzureBlobStorage] Failed to parse container URL: %v", err)
+	}
+
+	// Create a pipeline using the storage account's credentials.
+	p := azblob.NewPipeline(credential, azblob.PipelineOptions{})
+
+	return &AzureBlobStorage{
+		containerURL: azblob.NewContainerURL(*u, p),
+	}, nil
+}
+
+func (s *AzureBlobStorage) Put(key string, data []byte) error {
+	blobURL := s.containerURL.NewBlockBlobURL(key)
+
+	_, err := azblob.UploadBufferToBlockBlob(context.Background(), data, blobURL, azblob.UploadToBlockBlobOptions{
+		BlobHTTPHeaders: azblob.BlobHTTPHeaders{
+			ContentType: mime.TypeByExtension(path.Ext(key)),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("[AzureBlobStorage.Put] Failed to upload data to blob: %v", err)
+	}
+
+	fmt.Printf("[AzureBlobStorage.Put] Successfully uploaded data to blob '%s'\n", key)
+	return nil
+}
+
+func (s *AzureBlobStorage) Get(key string) ([]byte, error) {
+	blobURL := s.containerURL.NewBlobURL(key)
+
+	resp, err := blobURL.Download(context.Background(), 0, azblob.CountToEnd, azblob.BlobAccessConditions{}, false, azblob.ClientProvidedKeyOptions{})
+	if err != nil {
+		if isBlobNotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("[AzureBlobStorage.Get] Failed to download blob: %v", err)
+	}
+
+	body := resp.Body(azblob.RetryReaderOptions{MaxRetryRequests: 3})
+	defer body.Close()
+
+	return io.ReadAll(body)
+}
+
+func (s *AzureBlobStorage) List(prefix string) ([]string, error) {
+	var keys []string
+
+	for marker := (azblob.Marker{}); marker.NotDone(); {
+		resp, err := s.containerURL.ListBlobsFlatSegment(context.Background(), marker, azblob.ListBlobsSegmentOptions{
+			Prefix: prefix,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("[AzureBlobStorage.List] Failed to list blobs: %v", err)
+		}
+
+		for _, blob := range resp.Segment.BlobItems {
+			keys = append(keys, blob.Name)
+		}
+		marker = resp.NextMarker
+	}
+
+	return keys, nil
+}
+
+func (s *AzureBlobStorage) Delete(key string) error {
+	blobURL := s.containerURL.NewBlobURL(key)
+
+	_, err := blobURL.Delete(context.Background(), azblob.DeleteSnapshotsOptionInclude, azblob.BlobAccessConditions{})
+	if err != nil {
+		if isBlobNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("[AzureBlobStorage.Delete] Failed to delete blob: %v", err)
+	}
+
+	return nil
+}
+
+func isBlobNotFound(err error) bool {
+	var storageErr azblob.StorageError
+	if errors.As(err, &storageErr) {
+		return storageErr.ServiceCode() == azblob.ServiceCodeBlobNotFound
+	}
+	return false
+}
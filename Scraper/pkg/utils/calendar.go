@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"ntumods/data"
+	"ntumods/pkg/dto"
+	"os"
+	"path"
+)
+
+// LoadAcademicCalendars reads the calendars configured in the JSON file at filename, keyed by AcadYearSem, or the
+// calendars embedded in the binary when filename is empty
+func LoadAcademicCalendars(filename string) (map[string]dto.AcademicCalendar, error) {
+	if filename == "" {
+		return ParseAcademicCalendars(data.Calendar)
+	}
+
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("[LoadAcademicCalendars] Failed to read %s: %v", filename, err)
+	}
+
+	calendars, err := ParseAcademicCalendars(raw)
+	if err != nil {
+		return nil, fmt.Errorf("[LoadAcademicCalendars] %s: %v", filename, err)
+	}
+	return calendars, nil
+}
+
+// ParseAcademicCalendars parses calendars in the format of data/calendar.json, filling in the date of every week
+func ParseAcademicCalendars(raw []byte) (map[string]dto.AcademicCalendar, error) {
+	calendars := make(map[string]dto.AcademicCalendar)
+	if err := json.Unmarshal(raw, &calendars); err != nil {
+		return nil, fmt.Errorf("[ParseAcademicCalendars] Failed to parse calendars: %v", err)
+	}
+
+	for semester, calendar := range calendars {
+		calendar.AcadYearSem = semester
+		if calendar.StartDate != "" {
+			var err error
+			if calendar, err = calendar.Expand(); err != nil {
+				return nil, fmt.Errorf("[ParseAcademicCalendars] Invalid calendar for %s: %v", semester, err)
+			}
+		}
+		calendars[semester] = calendar
+	}
+
+	return calendars, nil
+}
+
+// LoadCalendar reads the calendar published for semester, returns ErrNotFound if it was not published
+func LoadCalendar(storage Storage, semester string) (*dto.AcademicCalendar, error) {
+	var calendar dto.AcademicCalendar
+	if err := GetJSON(storage, path.Join(semester, dto.CALENDAR_FILE), &calendar); err != nil {
+		return nil, err
+	}
+	return &calendar, nil
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadAcademicCalendarsEmbedded(t *testing.T) {
+	calendars, err := LoadAcademicCalendars("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calendar, exists := calendars["2023_1"]
+	if !exists {
+		t.Fatal("the embedded calendars have no 2023_1")
+	}
+	if len(calendar.Weeks) != 13 || calendar.Weeks[0].Start != "2023-08-14" {
+		t.Errorf("2023_1 has %d weeks starting %+v, expected 13 from 2023-08-14", len(calendar.Weeks), calendar.Weeks)
+	}
+	if calendar.Recess == nil || calendar.Recess.Start != "2023-10-02" {
+		t.Errorf("2023_1 has recess %+v, expected the week of 2023-10-02", calendar.Recess)
+	}
+}
+
+func TestLoadAcademicCalendarsOverride(t *testing.T) {
+	dir := t.TempDir()
+
+	filename := filepath.Join(dir, "calendar.json")
+	if err := os.WriteFile(filename, []byte(`{"2024_1": {"startDate": "2024-08-12", "recessAfterWeek": 7}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	calendars, err := LoadAcademicCalendars(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := calendars["2024_1"]; !exists || len(calendars) != 1 {
+		t.Errorf("the override has %v, expected only 2024_1", calendars)
+	}
+
+	// A configured file that cannot be read must not fall back to no calendars at all
+	if _, err = LoadAcademicCalendars(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("expected an error for a missing calendar file")
+	}
+
+	if err = os.WriteFile(filename, []byte(`{"2024_1": {"startDate": "12/08/2024"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = LoadAcademicCalendars(filename); err == nil {
+		t.Error("expected an error for an invalid start date")
+	}
+}
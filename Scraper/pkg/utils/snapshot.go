@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"fmt"
+	"ntumods/pkg/dto"
+	"path"
+	"sort"
+	"strings"
+)
+
+// LoadSemester reads every module published for semester, using its moduleList.json to find them. A module in the
+// list whose file is missing is skipped, and an error wrapping ErrNotFound is returned if the list itself is missing.
+func LoadSemester(storage Storage, semester string) ([]dto.Combined, error) {
+	var moduleList []dto.ModuleLite
+	if err := GetJSON(storage, path.Join(semester, dto.MODULE_LIST_FILE), &moduleList); err != nil {
+		return nil, fmt.Errorf("[LoadSemester] Failed to read module list of %s: %w", semester, err)
+	}
+
+	modules := make([]dto.Combined, 0, len(moduleList))
+	for _, m := range moduleList {
+		module, err := LoadModule(storage, semester, m.Code)
+		if err == ErrNotFound {
+			fmt.Println("[LoadSemester] Skipping", m.Code, "of", semester, "as it is in the module list but was not published")
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("[LoadSemester] Failed to read module %s of %s: %w", m.Code, semester, err)
+		}
+		modules = append(modules, *module)
+	}
+
+	return modules, nil
+}
+
+// LoadModule reads a single module published for semester, returns ErrNotFound if it was not published
+func LoadModule(storage Storage, semester string, code string) (*dto.Combined, error) {
+	var module dto.Combined
+	if err := GetJSON(storage, path.Join(semester, code+".json"), &module); err != nil {
+		return nil, err
+	}
+	return &module, nil
+}
+
+// ListSemesters returns every semester with a published module list, oldest first
+func ListSemesters(storage Storage) ([]string, error) {
+	keys, err := storage.List("")
+	if err != nil {
+		return nil, fmt.Errorf("[ListSemesters] Failed to list storage: %v", err)
+	}
+
+	var semesters []string
+	for _, key := range keys {
+		semester, file := path.Split(key)
+		semester = strings.TrimSuffix(semester, "/")
+		if file == dto.MODULE_LIST_FILE && semester != "" && !strings.Contains(semester, "/") {
+			semesters = append(semesters, semester)
+		}
+	}
+
+	sort.Strings(semesters)
+	return semesters, nil
+}
+
+// LoadChangelogs reads the changelogs of the last limit runs on semester, oldest first
+func LoadChangelogs(storage Storage, semester string, limit int) ([]dto.Changelog, error) {
+	keys, err := storage.List(path.Join(semester, dto.CHANGES_DIR) + "/")
+	if err != nil {
+		return nil, fmt.Errorf("[LoadChangelogs] Failed to list changelogs of %s: %v", semester, err)
+	}
+
+	// Changelogs are named after the time of their run, so their names sort in order
+	sort.Strings(keys)
+	if len(keys) > limit {
+		keys = keys[len(keys)-limit:]
+	}
+
+	changelogs := make([]dto.Changelog, 0, len(keys))
+	for _, key := range keys {
+		var changelog dto.Changelog
+		if err = GetJSON(storage, key, &changelog); err != nil {
+			return nil, fmt.Errorf("[LoadChangelogs] Failed to read %s: %v", key, err)
+		}
+		changelogs = append(changelogs, changelog)
+	}
+	return changelogs, nil
+}
+
+// PruneChangelogs deletes the changelogs of semester older than the last keep runs
+func PruneChangelogs(storage Storage, semester string, keep int) error {
+	keys, err := storage.List(path.Join(semester, dto.CHANGES_DIR) + "/")
+	if err != nil {
+		return fmt.Errorf("[PruneChangelogs] Failed to list changelogs of %s: %v", semester, err)
+	}
+
+	sort.Strings(keys)
+	for len(keys) > keep {
+		if err = storage.Delete(keys[0]); err != nil {
+			return fmt.Errorf("[PruneChangelogs] Failed to delete %s: %v", keys[0], err)
+		}
+		keys = keys[1:]
+	}
+	return nil
+}
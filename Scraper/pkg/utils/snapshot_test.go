@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"ntumods/pkg/dto"
+	"path"
+	"reflect"
+	"testing"
+)
+
+func TestLoadSemester(t *testing.T) {
+	storage := NewMemoryStorage()
+
+	if _, err := LoadSemester(storage, "2023_1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("LoadSemester of an unpublished semester returned %v, expected ErrNotFound", err)
+	}
+
+	moduleList := []dto.ModuleLite{{Code: "AB1201"}, {Code: "AC1103"}}
+	if err := PutJSON(storage, "2023_1/"+dto.MODULE_LIST_FILE, moduleList); err != nil {
+		t.Fatal(err)
+	}
+	var ab1201 dto.Combined
+	ab1201.Code = "AB1201"
+	if err := PutJSON(storage, "2023_1/AB1201.json", ab1201); err != nil {
+		t.Fatal(err)
+	}
+
+	// AC1103 is listed but its file is missing
+	modules, err := LoadSemester(storage, "2023_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(modules) != 1 || modules[0].Code != "AB1201" {
+		t.Errorf("LoadSemester returned %+v, expected only AB1201", modules)
+	}
+
+	if err := storage.Put("2023_1/AB1201.json", []byte("{")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSemester(storage, "2023_1"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("LoadSemester returned %v, expected the invalid AB1201.json to fail it", err)
+	}
+}
+
+func TestPruneChangelogs(t *testing.T) {
+	storage := NewMemoryStorage()
+
+	var keys []string
+	for i := 1; i <= 5; i++ {
+		key := path.Join("2023_1", dto.CHANGES_DIR, fmt.Sprintf("20230814T08000%d.000Z.json", i))
+		keys = append(keys, key)
+		if err := PutJSON(storage, key, dto.Changelog{Semester: "2023_1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	other := path.Join("2023_2", dto.CHANGES_DIR, "20230814T080000.000Z.json")
+	if err := PutJSON(storage, other, dto.Changelog{Semester: "2023_2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := PruneChangelogs(storage, "2023_1", 3); err != nil {
+		t.Fatal(err)
+	}
+
+	// The oldest are deleted, other semesters are left alone
+	expected := append(append([]string{}, keys[2:]...), other)
+	if kept, _ := storage.List(""); !reflect.DeepEqual(kept, expected) {
+		t.Errorf("kept %v, expected %v", kept, expected)
+	}
+
+	if err := PruneChangelogs(storage, "2023_1", 3); err != nil {
+		t.Fatal(err)
+	}
+	if kept, _ := storage.List("2023_1/"); len(kept) != 3 {
+		t.Errorf("kept %v after pruning again, expected the same 3", kept)
+	}
+}
@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"ntumods/pkg/dto"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotFound is returned by a Storage when the requested key does not exist
+var ErrNotFound = errors.New("key not found")
+
+// Storage is where the scraper publishes its results, keys are slash separated paths such as "2023_1/AB1201.json".
+// Get returns ErrNotFound for a missing key, while Delete of a missing key succeeds as it would on S3.
+type Storage interface {
+	Put(key string, data []byte) error
+	Get(key string) ([]byte, error)
+	List(prefix string) ([]string, error)
+	Delete(key string) error
+}
+
+// StorageConfig selects and configures a Storage backend
+type StorageConfig struct {
+	Backend          string
+	LocalPath        string
+	AzureAccountName string
+	AzureAccountKey  string
+	AzureContainer   string
+	S3Endpoint       string
+	S3Region         string
+	S3Bucket         string
+	S3AccessKey      string
+	S3SecretKey      string
+	S3PathStyle      bool
+}
+
+// StorageConfigFromEnv reads the storage configuration from the environment, falling back to Azure Blob Storage
+func StorageConfigFromEnv() StorageConfig {
+	config := StorageConfig{
+		Backend:          os.Getenv("STORAGE_BACKEND"),
+		LocalPath:        os.Getenv("LOCAL_STORAGE_PATH"),
+		AzureAccountName: os.Getenv("AZURE_STORAGE_ACCOUNT_NAME"),
+		AzureAccountKey:  os.Getenv("AZURE_STORAGE_ACCOUNT_ACCESS_KEY"),
+		AzureContainer:   os.Getenv("AZURE_STORAGE_CONTAINER_NAME"),
+		S3Endpoint:       os.Getenv("S3_ENDPOINT"),
+		S3Region:         os.Getenv("S3_REGION"),
+		S3Bucket:         os.Getenv("S3_BUCKET"),
+		S3AccessKey:      os.Getenv("S3_ACCESS_KEY_ID"),
+		S3SecretKey:      os.Getenv("S3_SECRET_ACCESS_KEY"),
+		S3PathStyle:      os.Getenv("S3_VIRTUAL_HOSTED_STYLE") != "true",
+	}
+
+	if config.Backend == "" {
+		config.Backend = dto.STORAGE_AZURE
+	}
+
+	if config.LocalPath == "" {
+		config.LocalPath = filepath.Join("..", "out")
+	}
+
+	if config.AzureAccountName == "" {
+		config.AzureAccountName = dto.ACCOUNT_NAME
+	}
+
+	if config.AzureContainer == "" {
+		config.AzureContainer = dto.CONTAINER_NAME
+	}
+
+	return config
+}
+
+func NewStorage(config StorageConfig) (Storage, error) {
+	switch strings.ToLower(config.Backend) {
+	case dto.STORAGE_AZURE:
+		return NewAzureBlobStorage(config.AzureAccountName, config.AzureAccountKey, config.AzureContainer)
+	case dto.STORAGE_LOCAL:
+		return NewLocalStorage(config.LocalPath), nil
+	case dto.STORAGE_MEMORY:
+		return NewMemoryStorage(), nil
+	case dto.STORAGE_S3:
+		return NewS3Storage(config.S3Endpoint, config.S3Region, config.S3Bucket, config.S3AccessKey, config.S3SecretKey, config.S3PathStyle)
+	default:
+		return nil, fmt.Errorf("[NewStorage] Unknown storage backend: %s", config.Backend)
+	}
+}
+
+// PutJSON marshals data and stores it under key
+func PutJSON(storage Storage, key string, data interface{}) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		fmt.Println("[PutJSON] Error marshaling data:", err)
+		return err
+	}
+
+	return storage.Put(key, jsonData)
+}
+
+// GetJSON reads the value stored under key and unmarshals it into out
+func GetJSON(storage Storage, key string, out interface{}) error {
+	data, err := storage.Get(key)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, out)
+}
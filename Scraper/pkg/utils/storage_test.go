@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testStorage checks the behaviour every Storage backend shares
+func testStorage(t *testing.T, storage Storage) {
+	if keys, err := storage.List(""); err != nil || len(keys) != 0 {
+		t.Fatalf("List of an empty storage returned %v, %v", keys, err)
+	}
+
+	objects := map[string]string{
+		"2023_1/AB1201.json":      `{"code":"AB1201"}`,
+		"2023_1/AC1103.json":      `{"code":"AC1103"}`,
+		"2023_1/changes/123.json": `{}`,
+		"2023_10/AB1201.json":     `{"code":"AB1201"}`,
+		"semesters.json":          `["2023_1"]`,
+	}
+	for key, data := range objects {
+		if err := storage.Put(key, []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for key, data := range objects {
+		got, err := storage.Get(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != data {
+			t.Errorf("Get(%q) returned %q, expected %q", key, got, data)
+		}
+	}
+
+	// Put replaces the value
+	if err := storage.Put("semesters.json", []byte(`["2023_1","2023_2"]`)); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := storage.Get("semesters.json"); string(got) != `["2023_1","2023_2"]` {
+		t.Errorf("Get after a second Put returned %q", got)
+	}
+
+	if _, err := storage.Get("2023_1/missing.json"); err != ErrNotFound {
+		t.Errorf("Get of a missing key returned %v, expected ErrNotFound", err)
+	}
+
+	tests := []struct {
+		prefix   string
+		expected []string
+	}{
+		{"2023_1/", []string{"2023_1/AB1201.json", "2023_1/AC1103.json", "2023_1/changes/123.json"}},
+		{"2023_1/changes/", []string{"2023_1/changes/123.json"}},
+		{"2023_1", []string{"2023_1/AB1201.json", "2023_1/AC1103.json", "2023_1/changes/123.json", "2023_10/AB1201.json"}},
+		{"2024_1/", nil},
+	}
+	for _, tt := range tests {
+		keys, err := storage.List(tt.prefix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(keys) == 0 && len(tt.expected) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(keys, tt.expected) {
+			t.Errorf("List(%q) returned %v, expected %v", tt.prefix, keys, tt.expected)
+		}
+	}
+
+	if err := storage.Delete("2023_1/AB1201.json"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := storage.Get("2023_1/AB1201.json"); err != ErrNotFound {
+		t.Errorf("Get after Delete returned %v, expected ErrNotFound", err)
+	}
+	if keys, _ := storage.List("2023_1/"); !reflect.DeepEqual(keys, []string{"2023_1/AC1103.json", "2023_1/changes/123.json"}) {
+		t.Errorf("List after Delete returned %v", keys)
+	}
+
+	if err := storage.Delete("2023_1/AB1201.json"); err != nil {
+		t.Errorf("Delete of a missing key returned %v", err)
+	}
+}
+
+func TestLocalStorage(t *testing.T) {
+	testStorage(t, NewLocalStorage(t.TempDir()))
+}
+
+func TestLocalStorageMissingRoot(t *testing.T) {
+	storage := NewLocalStorage(t.TempDir() + "/out")
+	if keys, err := storage.List(""); err != nil || len(keys) != 0 {
+		t.Errorf("List of a missing directory returned %v, %v", keys, err)
+	}
+	testStorage(t, storage)
+}
+
+func TestMemoryStorage(t *testing.T) {
+	testStorage(t, NewMemoryStorage())
+}
+
+func TestMemoryStorageCopies(t *testing.T) {
+	storage := NewMemoryStorage()
+
+	data := []byte("{}")
+	if err := storage.Put("a.json", data); err != nil {
+		t.Fatal(err)
+	}
+	data[0] = '['
+
+	got, _ := storage.Get("a.json")
+	got[1] = ']'
+	if again, _ := storage.Get("a.json"); string(again) != "{}" {
+		t.Errorf("the stored value changed to %q with the slices passed in and out", again)
+	}
+}
+
+func TestS3Storage(t *testing.T) {
+	_, storage := newFakeS3(t, "ntumods", 2)
+	testStorage(t, storage)
+}
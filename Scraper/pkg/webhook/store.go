@@ -0,0 +1,153 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"ntumods/pkg/dto"
+	"ntumods/pkg/utils"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Keys the Store keeps its data under
+const (
+	webhooksKey    = "webhooks.json"
+	deadLetterDir  = "deadLetters"
+	secretByteSize = 32
+)
+
+// Store keeps the registered webhooks and the dead-letter log in a storage. The webhooks hold their secrets, so the
+// storage must not be the one the modules are published to.
+type Store struct {
+	storage utils.Storage
+	guard   guard
+	mu      sync.Mutex
+}
+
+// NewStore creates a Store refusing webhooks to loopback, link-local and private addresses, except on allowedHosts
+func NewStore(storage utils.Storage, allowedHosts []string) *Store {
+	return &Store{storage: storage, guard: newGuard(allowedHosts)}
+}
+
+// List returns every registered webhook
+func (s *Store) List() ([]dto.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.list()
+}
+
+// Add registers webhook, giving it an ID and, unless it has one, a secret
+func (s *Store) Add(webhook dto.Webhook) (dto.Webhook, error) {
+	err := s.guard.checkURL(webhook.URL)
+	if err != nil {
+		return dto.Webhook{}, fmt.Errorf("[Store.Add] %v", err)
+	}
+
+	for _, event := range webhook.Events {
+		switch event {
+		case dto.WEBHOOK_ADDED, dto.WEBHOOK_REMOVED, dto.WEBHOOK_DETAILS, dto.WEBHOOK_SCHEDULE, dto.WEBHOOK_EXAM:
+		default:
+			return dto.Webhook{}, fmt.Errorf("[Store.Add] Unknown event %q", event)
+		}
+	}
+
+	if webhook.ID, err = randomHex(8); err != nil {
+		return dto.Webhook{}, fmt.Errorf("[Store.Add] Failed to generate ID: %v", err)
+	}
+	if webhook.Secret == "" {
+		if webhook.Secret, err = randomHex(secretByteSize); err != nil {
+			return dto.Webhook{}, fmt.Errorf("[Store.Add] Failed to generate secret: %v", err)
+		}
+	}
+	for i, code := range webhook.Codes {
+		webhook.Codes[i] = strings.ToUpper(strings.TrimSpace(code))
+	}
+	webhook.CreatedAt = time.Now().Format(time.RFC3339)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	webhooks, err := s.list()
+	if err != nil {
+		return dto.Webhook{}, err
+	}
+
+	webhooks = append(webhooks, webhook)
+	if err = utils.PutJSON(s.storage, webhooksKey, webhooks); err != nil {
+		return dto.Webhook{}, fmt.Errorf("[Store.Add] Failed to save webhooks: %v", err)
+	}
+
+	return webhook, nil
+}
+
+// Remove unregisters the webhook with id, returns utils.ErrNotFound if there is none
+func (s *Store) Remove(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	webhooks, err := s.list()
+	if err != nil {
+		return err
+	}
+
+	for i, webhook := range webhooks {
+		if webhook.ID != id {
+			continue
+		}
+
+		webhooks = append(webhooks[:i], webhooks[i+1:]...)
+		if err = utils.PutJSON(s.storage, webhooksKey, webhooks); err != nil {
+			return fmt.Errorf("[Store.Remove] Failed to save webhooks: %v", err)
+		}
+		return nil
+	}
+
+	return utils.ErrNotFound
+}
+
+// DeadLetter records a payload that could not be delivered, one key per delivery
+func (s *Store) DeadLetter(letter dto.WebhookDeadLetter) error {
+	key := path.Join(deadLetterDir, letter.Payload.WebhookID, letter.Payload.DeliveryID+".json")
+	if err := utils.PutJSON(s.storage, key, letter); err != nil {
+		return fmt.Errorf("[Store.DeadLetter] Failed to save dead letter: %v", err)
+	}
+	return nil
+}
+
+// DeadLetters returns every payload that could not be delivered
+func (s *Store) DeadLetters() ([]dto.WebhookDeadLetter, error) {
+	keys, err := s.storage.List(deadLetterDir + "/")
+	if err != nil {
+		return nil, fmt.Errorf("[Store.DeadLetters] Failed to list dead letters: %v", err)
+	}
+
+	letters := make([]dto.WebhookDeadLetter, 0, len(keys))
+	for _, key := range keys {
+		var letter dto.WebhookDeadLetter
+		if err = utils.GetJSON(s.storage, key, &letter); err != nil {
+			return nil, fmt.Errorf("[Store.DeadLetters] Failed to read %s: %v", key, err)
+		}
+		letters = append(letters, letter)
+	}
+	return letters, nil
+}
+
+func (s *Store) list() ([]dto.Webhook, error) {
+	webhooks := []dto.Webhook{}
+	err := utils.GetJSON(s.storage, webhooksKey, &webhooks)
+	if err != nil && err != utils.ErrNotFound {
+		return nil, fmt.Errorf("[Store.List] Failed to read webhooks: %v", err)
+	}
+	return webhooks, nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
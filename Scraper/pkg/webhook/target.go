@@ -0,0 +1,106 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// guard keeps webhooks out of the network the server runs in. Anyone able to register a webhook could otherwise make
+// the server POST to loopback, link-local (such as cloud metadata endpoints) and private addresses, so these are
+// refused unless their host is explicitly allowed.
+type guard struct {
+	allowedHosts map[string]bool
+}
+
+func newGuard(allowedHosts []string) guard {
+	g := guard{allowedHosts: make(map[string]bool)}
+	for _, host := range allowedHosts {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			g.allowedHosts[host] = true
+		}
+	}
+	return g
+}
+
+// checkURL returns an error unless raw is an http or https URL whose host is allowed or only resolves to public
+// addresses
+func (g guard) checkURL(raw string) error {
+	target, err := url.Parse(raw)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Hostname() == "" {
+		return fmt.Errorf("URL %q is not an http or https URL", raw)
+	}
+
+	host := target.Hostname()
+	if g.allowed(host) {
+		return nil
+	}
+
+	_, err = g.resolve(context.Background(), host)
+	return err
+}
+
+// dialContext dials the addresses the host of addr resolves to once they are checked, so that a host cannot resolve
+// to a public address when registered and to a private one when delivered to
+func (g guard) dialContext(ctx context.Context, network string, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	if g.allowed(host) {
+		return dialer.DialContext(ctx, network, addr)
+	}
+
+	ips, err := g.resolve(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var conn net.Conn
+	for _, ip := range ips {
+		if conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port)); err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
+
+func (g guard) allowed(host string) bool {
+	return g.allowedHosts[strings.ToLower(host)]
+}
+
+// resolve returns the addresses of host, or an error if any of them is one webhooks must not reach
+func (g guard) resolve(ctx context.Context, host string) ([]net.IP, error) {
+	var ips []net.IP
+	if ip := net.ParseIP(host); ip != nil {
+		ips = []net.IP{ip}
+	} else {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve %s: %v", host, err)
+		}
+		for _, addr := range addrs {
+			ips = append(ips, addr.IP)
+		}
+	}
+
+	for _, ip := range ips {
+		if !isPublic(ip) {
+			return nil, fmt.Errorf("%s resolves to %s, which is not a public address", host, ip)
+		}
+	}
+	return ips, nil
+}
+
+func isPublic(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsUnspecified()
+}
@@ -0,0 +1,303 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"ntumods/pkg/dto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config configures how a Dispatcher delivers payloads, zero values fall back to the defaults in dto/constants.go
+type Config struct {
+	// Client, when set, replaces the default client, which refuses to connect to the addresses the store refuses
+	Client      *http.Client
+	MaxAttempts int
+	// RetryDelay is the delay before the first retry, it doubles with every retry after
+	RetryDelay time.Duration
+}
+
+// Dispatcher delivers the changes of each scrape to the registered webhooks
+type Dispatcher struct {
+	store       *Store
+	client      *http.Client
+	maxAttempts int
+	retryDelay  time.Duration
+
+	// queue holds the changelogs passed to Enqueue, delivered one after the other by a single goroutine that runs
+	// while the queue is not empty
+	mu      sync.Mutex
+	queue   []dto.Changelog
+	running bool
+	pending sync.WaitGroup
+}
+
+func NewDispatcher(store *Store, config Config) *Dispatcher {
+	d := &Dispatcher{
+		store:       store,
+		client:      config.Client,
+		maxAttempts: config.MaxAttempts,
+		retryDelay:  config.RetryDelay,
+	}
+
+	if d.client == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.DialContext = store.guard.dialContext
+		d.client = &http.Client{Timeout: dto.REQUEST_TIMEOUT, Transport: transport}
+	}
+	if d.maxAttempts <= 0 {
+		d.maxAttempts = dto.WEBHOOK_MAX_ATTEMPTS
+	}
+	if d.retryDelay <= 0 {
+		d.retryDelay = dto.WEBHOOK_RETRY_DELAY
+	}
+
+	return d
+}
+
+// Enqueue queues changelog to be dispatched in the background, so that a scrape does not wait for slow or failing
+// webhooks. Changelogs are dispatched in the order they were queued.
+func (d *Dispatcher) Enqueue(changelog dto.Changelog) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.pending.Add(1)
+	d.queue = append(d.queue, changelog)
+	if !d.running {
+		d.running = true
+		go d.run()
+	}
+}
+
+// Wait blocks until every changelog queued so far is delivered or dead-lettered
+func (d *Dispatcher) Wait() {
+	d.pending.Wait()
+}
+
+func (d *Dispatcher) run() {
+	for {
+		d.mu.Lock()
+		if len(d.queue) == 0 {
+			d.running = false
+			d.mu.Unlock()
+			return
+		}
+		changelog := d.queue[0]
+		d.queue = d.queue[1:]
+		d.mu.Unlock()
+
+		if err := d.Dispatch(changelog); err != nil {
+			fmt.Println("Error notifying webhooks:", err)
+		}
+		d.pending.Done()
+	}
+}
+
+// Dispatch sends every webhook the part of changelog it is filtered to, skipping webhooks without any such change.
+// It returns once every payload is delivered or dead-lettered.
+func (d *Dispatcher) Dispatch(changelog dto.Changelog) error {
+	webhooks, err := d.store.List()
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for _, webhook := range webhooks {
+		filtered := Filter(changelog, webhook)
+		if len(filtered.Added) == 0 && len(filtered.Removed) == 0 && len(filtered.Changed) == 0 {
+			continue
+		}
+
+		deliveryID, err := randomHex(8)
+		if err != nil {
+			return fmt.Errorf("[Dispatch] Failed to generate delivery ID: %v", err)
+		}
+
+		payload := dto.WebhookPayload{DeliveryID: deliveryID, WebhookID: webhook.ID, Changelog: filtered}
+
+		wg.Add(1)
+		go func(webhook dto.Webhook) {
+			defer wg.Done()
+			d.deliver(webhook, payload)
+		}(webhook)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// deliver POSTs payload to webhook, retrying with exponential backoff and dead-lettering it once every attempt failed
+func (d *Dispatcher) deliver(webhook dto.Webhook, payload dto.WebhookPayload) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		fmt.Println("[deliver] Error marshaling payload:", err)
+		return
+	}
+
+	delay := d.retryDelay
+	for attempt := 1; ; attempt++ {
+		err = d.post(webhook, payload.DeliveryID, body)
+		if err == nil {
+			return
+		}
+
+		if attempt == d.maxAttempts {
+			break
+		}
+
+		fmt.Printf("[deliver] Attempt %d to %s failed, retrying in %s: %v\n", attempt, webhook.URL, delay, err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+
+	fmt.Printf("[deliver] Giving up on %s after %d attempts: %v\n", webhook.URL, d.maxAttempts, err)
+
+	letter := dto.WebhookDeadLetter{
+		URL:       webhook.URL,
+		Attempts:  d.maxAttempts,
+		LastError: err.Error(),
+		FailedAt:  time.Now().Format(time.RFC3339),
+		Payload:   payload,
+	}
+	if err = d.store.DeadLetter(letter); err != nil {
+		fmt.Println(err)
+	}
+}
+
+func (d *Dispatcher) post(webhook dto.Webhook, deliveryID string, body []byte) error {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", dto.USER_AGENT)
+	req.Header.Set(dto.WEBHOOK_DELIVERY_HEADER, deliveryID)
+	req.Header.Set(dto.WEBHOOK_TIMESTAMP_HEADER, timestamp)
+	req.Header.Set(dto.WEBHOOK_SIGNATURE_HEADER, "sha256="+Sign(webhook.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>" keyed by secret
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature header of a delivery, receivers should also reject timestamps that are too old
+func Verify(secret string, timestamp string, signature string, body []byte) bool {
+	expected := "sha256=" + Sign(secret, timestamp, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// Filter returns the part of changelog that webhook is filtered to by module code, faculty and kind of change
+func Filter(changelog dto.Changelog, webhook dto.Webhook) dto.Changelog {
+	filtered := changelog
+	filtered.Added = []dto.ModuleChange{}
+	filtered.Removed = []dto.ModuleChange{}
+	filtered.Changed = []dto.ModuleChange{}
+
+	if wants(webhook, dto.WEBHOOK_ADDED) {
+		for _, change := range changelog.Added {
+			if matches(webhook, change) {
+				filtered.Added = append(filtered.Added, change)
+			}
+		}
+	}
+
+	if wants(webhook, dto.WEBHOOK_REMOVED) {
+		for _, change := range changelog.Removed {
+			if matches(webhook, change) {
+				filtered.Removed = append(filtered.Removed, change)
+			}
+		}
+	}
+
+	for _, change := range changelog.Changed {
+		if !matches(webhook, change) {
+			continue
+		}
+
+		// Only keep the kinds of change the webhook asked for
+		if !wants(webhook, dto.WEBHOOK_DETAILS) {
+			change.Fields = nil
+		}
+		if !wants(webhook, dto.WEBHOOK_SCHEDULE) {
+			change.AddedIndexes = nil
+			change.RemovedIndexes = nil
+			change.Classes = nil
+		}
+		if !wants(webhook, dto.WEBHOOK_EXAM) {
+			change.Exam = nil
+		}
+
+		if len(change.Fields) > 0 || len(change.AddedIndexes) > 0 || len(change.RemovedIndexes) > 0 ||
+			len(change.Classes) > 0 || change.Exam != nil {
+			filtered.Changed = append(filtered.Changed, change)
+		}
+	}
+
+	return filtered
+}
+
+func wants(webhook dto.Webhook, event string) bool {
+	if len(webhook.Events) == 0 {
+		return true
+	}
+	for _, e := range webhook.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func matches(webhook dto.Webhook, change dto.ModuleChange) bool {
+	if len(webhook.Codes) > 0 {
+		found := false
+		for _, code := range webhook.Codes {
+			if code == change.Code {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(webhook.Faculties) > 0 {
+		found := false
+		for _, faculty := range webhook.Faculties {
+			if strings.EqualFold(faculty, change.Faculty.Code) {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,226 @@
+package webhook_test
+
+import (
+	"ntumods/pkg/dto"
+	"ntumods/pkg/utils"
+	"ntumods/pkg/webhook"
+	"ntumods/pkg/webhooktest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const secret = "c2VjcmV0"
+
+// newTestDispatcher registers receiver and returns a dispatcher making at most maxAttempts to deliver to it
+func newTestDispatcher(t *testing.T, receiver *webhooktest.Receiver, maxAttempts int) (*webhook.Dispatcher, *webhook.Store, dto.Webhook) {
+	store := webhook.NewStore(utils.NewMemoryStorage(), []string{"127.0.0.1"})
+	registered, err := store.Add(dto.Webhook{URL: receiver.URL, Secret: secret})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dispatcher := webhook.NewDispatcher(store, webhook.Config{MaxAttempts: maxAttempts, RetryDelay: time.Millisecond})
+	return dispatcher, store, registered
+}
+
+func newTestReceiver(t *testing.T) *webhooktest.Receiver {
+	receiver := webhooktest.NewReceiver(secret)
+	t.Cleanup(receiver.Close)
+	return receiver
+}
+
+func testChangelog(semester string) dto.Changelog {
+	return dto.Changelog{
+		Semester: semester,
+		Changed: []dto.ModuleChange{{
+			Code:   "AB1201",
+			Fields: []dto.FieldChange{{Field: "title", Before: "CORPORATE FINANCE", After: "FINANCIAL MANAGEMENT"}},
+		}},
+	}
+}
+
+func deadLetters(t *testing.T, store *webhook.Store) []dto.WebhookDeadLetter {
+	letters, err := store.DeadLetters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return letters
+}
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"deliveryId":"1"}`)
+	signature := "sha256=" + webhook.Sign(secret, "1692000000", body)
+
+	if !webhook.Verify(secret, "1692000000", signature, body) {
+		t.Error("the signature does not verify")
+	}
+	if webhook.Verify(secret, "1692000001", signature, body) {
+		t.Error("the signature verifies with another timestamp")
+	}
+	if webhook.Verify(secret, "1692000000", signature, []byte(`{"deliveryId":"2"}`)) {
+		t.Error("the signature verifies with another body")
+	}
+	if webhook.Verify("other", "1692000000", signature, body) {
+		t.Error("the signature verifies with another secret")
+	}
+}
+
+func TestDispatch(t *testing.T) {
+	receiver := newTestReceiver(t)
+	dispatcher, store, registered := newTestDispatcher(t, receiver, 1)
+
+	if err := dispatcher.Dispatch(testChangelog("2023_1")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The receiver rejects any delivery whose signature header does not match its secret
+	if err := receiver.Err(); err != nil {
+		t.Fatal(err)
+	}
+	deliveries := receiver.Deliveries()
+	if len(deliveries) != 1 || deliveries[0].WebhookID != registered.ID || deliveries[0].Changelog.Changed[0].Code != "AB1201" {
+		t.Errorf("deliveries are %+v, expected the changes of AB1201 for webhook %s", deliveries, registered.ID)
+	}
+	if letters := deadLetters(t, store); len(letters) != 0 {
+		t.Errorf("%d dead letters, expected none", len(letters))
+	}
+}
+
+func TestDispatchWrongSecret(t *testing.T) {
+	receiver := newTestReceiver(t)
+
+	store := webhook.NewStore(utils.NewMemoryStorage(), []string{"127.0.0.1"})
+	if _, err := store.Add(dto.Webhook{URL: receiver.URL, Secret: "other"}); err != nil {
+		t.Fatal(err)
+	}
+	dispatcher := webhook.NewDispatcher(store, webhook.Config{MaxAttempts: 1})
+
+	if err := dispatcher.Dispatch(testChangelog("2023_1")); err != nil {
+		t.Fatal(err)
+	}
+	if receiver.Err() == nil || len(receiver.Deliveries()) != 0 {
+		t.Error("a delivery signed with another secret was accepted")
+	}
+}
+
+func TestDispatchRetries(t *testing.T) {
+	receiver := newTestReceiver(t)
+	dispatcher, store, _ := newTestDispatcher(t, receiver, 3)
+
+	receiver.FailNext(2)
+	if err := dispatcher.Dispatch(testChangelog("2023_1")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := receiver.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if attempts, deliveries := receiver.Attempts(), len(receiver.Deliveries()); attempts != 3 || deliveries != 1 {
+		t.Errorf("%d attempts and %d deliveries, expected 3 attempts and 1 delivery", attempts, deliveries)
+	}
+	if letters := deadLetters(t, store); len(letters) != 0 {
+		t.Errorf("%d dead letters, expected none", len(letters))
+	}
+}
+
+func TestDispatchDeadLetter(t *testing.T) {
+	receiver := newTestReceiver(t)
+	dispatcher, store, registered := newTestDispatcher(t, receiver, 3)
+
+	receiver.FailNext(3)
+	if err := dispatcher.Dispatch(testChangelog("2023_1")); err != nil {
+		t.Fatal(err)
+	}
+
+	if attempts, deliveries := receiver.Attempts(), len(receiver.Deliveries()); attempts != 3 || deliveries != 0 {
+		t.Errorf("%d attempts and %d deliveries, expected 3 attempts and none delivered", attempts, deliveries)
+	}
+
+	letters := deadLetters(t, store)
+	if len(letters) != 1 {
+		t.Fatalf("%d dead letters, expected 1", len(letters))
+	}
+	letter := letters[0]
+	if letter.URL != receiver.URL || letter.Attempts != 3 || letter.Payload.WebhookID != registered.ID || letter.LastError == "" {
+		t.Errorf("dead letter is %+v", letter)
+	}
+	if changed := letter.Payload.Changelog.Changed; len(changed) != 1 || changed[0].Code != "AB1201" {
+		t.Errorf("dead letter holds changes %+v, expected the changes of AB1201", changed)
+	}
+}
+
+func TestEnqueue(t *testing.T) {
+	receiver := newTestReceiver(t)
+	dispatcher, _, _ := newTestDispatcher(t, receiver, 2)
+
+	// The first changelog is retried before the second is delivered
+	receiver.FailNext(1)
+	dispatcher.Enqueue(testChangelog("2023_1"))
+	dispatcher.Enqueue(testChangelog("2023_2"))
+	dispatcher.Wait()
+
+	if err := receiver.Err(); err != nil {
+		t.Fatal(err)
+	}
+	deliveries := receiver.Deliveries()
+	if len(deliveries) != 2 || deliveries[0].Changelog.Semester != "2023_1" || deliveries[1].Changelog.Semester != "2023_2" {
+		t.Errorf("deliveries are %+v, expected 2023_1 then 2023_2", deliveries)
+	}
+}
+
+func TestAddTargets(t *testing.T) {
+	store := webhook.NewStore(utils.NewMemoryStorage(), []string{"Hooks.internal"})
+
+	tests := []struct {
+		url      string
+		accepted bool
+	}{
+		{"https://203.0.113.10/hook", true},
+		{"http://hooks.internal:8080/hook", true},
+		{"ftp://203.0.113.10/hook", false},
+		{"/hook", false},
+		{"http://127.0.0.1:8080/hook", false},
+		{"http://localhost/hook", false},
+		{"http://[::1]/hook", false},
+		{"http://[::ffff:127.0.0.1]/hook", false},
+		{"http://0.0.0.0/hook", false},
+		{"http://169.254.169.254/latest/meta-data", false},
+		{"http://[fe80::1]/hook", false},
+		{"http://10.0.0.5/hook", false},
+		{"http://172.16.0.1/hook", false},
+		{"http://192.168.1.1/hook", false},
+		{"http://[fd00::1]/hook", false},
+	}
+
+	for _, tt := range tests {
+		_, err := store.Add(dto.Webhook{URL: tt.url})
+		if accepted := err == nil; accepted != tt.accepted {
+			t.Errorf("Add(%q) returned %v, expected accepted to be %v", tt.url, err, tt.accepted)
+		}
+	}
+}
+
+func TestDispatchPrivateAddress(t *testing.T) {
+	receiver := newTestReceiver(t)
+
+	// A webhook saved before its address was refused, or whose host now resolves to a private address, is not
+	// connected to either
+	storage := utils.NewMemoryStorage()
+	if _, err := webhook.NewStore(storage, []string{"127.0.0.1"}).Add(dto.Webhook{URL: receiver.URL, Secret: secret}); err != nil {
+		t.Fatal(err)
+	}
+	store := webhook.NewStore(storage, nil)
+	dispatcher := webhook.NewDispatcher(store, webhook.Config{MaxAttempts: 1})
+
+	if err := dispatcher.Dispatch(testChangelog("2023_1")); err != nil {
+		t.Fatal(err)
+	}
+
+	if attempts := receiver.Attempts(); attempts != 0 {
+		t.Errorf("%d attempts, expected none", attempts)
+	}
+	if letters := deadLetters(t, store); len(letters) != 1 || !strings.Contains(letters[0].LastError, "not a public address") {
+		t.Errorf("dead letters are %+v, expected one refused as private", letters)
+	}
+}
@@ -0,0 +1,110 @@
+// Package webhooktest provides a local webhook receiver for end-to-end tests of webhook delivery.
+//
+// The receiver checks the signature of every delivery against its secret, any mismatch is reported by Err.
+// FailNext makes it answer with an error so that retries and dead-lettering can be exercised.
+package webhooktest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"ntumods/pkg/dto"
+	"ntumods/pkg/webhook"
+	"strings"
+	"sync"
+)
+
+// Receiver is a webhook endpoint, register its URL together with its secret
+type Receiver struct {
+	*httptest.Server
+
+	secret string
+
+	mu         sync.Mutex
+	deliveries []dto.WebhookPayload
+	attempts   int
+	failures   int
+	errs       []string
+}
+
+func NewReceiver(secret string) *Receiver {
+	r := &Receiver{secret: secret}
+	r.Server = httptest.NewServer(http.HandlerFunc(r.handle))
+	return r
+}
+
+// FailNext makes the next n deliveries fail with 503 Service Unavailable
+func (r *Receiver) FailNext(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures = n
+}
+
+// Deliveries returns every payload accepted so far, in order of arrival
+func (r *Receiver) Deliveries() []dto.WebhookPayload {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]dto.WebhookPayload(nil), r.deliveries...)
+}
+
+// Attempts counts every request received, including the ones failed on purpose
+func (r *Receiver) Attempts() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.attempts
+}
+
+// Err returns an error describing every delivery that was malformed or not signed with the secret
+func (r *Receiver) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("webhooktest: %d invalid deliveries:\n%s", len(r.errs), strings.Join(r.errs, "\n"))
+}
+
+func (r *Receiver) handle(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.attempts++
+
+	if r.failures > 0 {
+		r.failures--
+		http.Error(w, "failing on purpose", http.StatusServiceUnavailable)
+		return
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		r.errs = append(r.errs, fmt.Sprintf("unable to read body: %v", err))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	timestamp := req.Header.Get(dto.WEBHOOK_TIMESTAMP_HEADER)
+	signature := req.Header.Get(dto.WEBHOOK_SIGNATURE_HEADER)
+	if !webhook.Verify(r.secret, timestamp, signature, body) {
+		r.errs = append(r.errs, fmt.Sprintf("signature %q does not match the body", signature))
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var payload dto.WebhookPayload
+	if err = json.Unmarshal(body, &payload); err != nil {
+		r.errs = append(r.errs, fmt.Sprintf("unable to parse payload: %v", err))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if payload.DeliveryID != req.Header.Get(dto.WEBHOOK_DELIVERY_HEADER) {
+		r.errs = append(r.errs, fmt.Sprintf("delivery header %q does not match payload %q", req.Header.Get(dto.WEBHOOK_DELIVERY_HEADER), payload.DeliveryID))
+	}
+
+	r.deliveries = append(r.deliveries, payload)
+	w.WriteHeader(http.StatusNoContent)
+}
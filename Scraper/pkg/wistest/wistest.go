@@ -0,0 +1,226 @@
+// Package wistest provides a fake NTU WIS server for end-to-end tests of the scraper.
+//
+// The server serves the fixture pages below fixtures/ and checks that every request carries the form
+// fields built by the scraper, any mismatch is reported by Err.
+package wistest
+
+import (
+	"embed"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"ntumods/pkg/dto"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+//go:embed fixtures
+var fixtures embed.FS
+
+// The fixtures describe a single semester with two programmes. Year 1 shows AB1201 as core and AC1104 as an elective
+// to it, year 2 does not say.
+const SEMESTER = "2023_1"
+
+var PROGRAMMES = []string{"ACC;GA;1;F", "ACC;GA;2;F"}
+
+// MODULES are the module codes found across every programme of SEMESTER
+var MODULES = []string{"AB1201", "AC1103", "AC1104", "AC2101"}
+
+// Request is a request received by the Server
+type Request struct {
+	Path string
+	Form url.Values
+}
+
+// Server is a fake NTU WIS, point a scraper.Config.BaseURL at its URL
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []Request
+	errs     []string
+	// failing lists the paths answered with 503 Service Unavailable
+	failing map[string]bool
+}
+
+var (
+	acadYearSemPattern         = regexp.MustCompile(`^\d{4}_\w$`)
+	scheduleAcadYearSemPattern = regexp.MustCompile(`^\d{4};\w$`)
+)
+
+func NewServer() *Server {
+	s := &Server{failing: make(map[string]bool)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(dto.CONTENT_OF_COURSES_INIT, s.handleCourseListInit)
+	mux.HandleFunc(dto.CONTENT_OF_COURSES, s.handleContentOfCourses)
+	mux.HandleFunc(dto.CLASS_SCHEDULE, s.handleClassSchedule)
+	mux.HandleFunc(dto.EXAM_SCHEDULE, s.handleExamSchedule)
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		failing := s.failing[r.URL.Path]
+		s.mu.Unlock()
+
+		if failing {
+			http.Error(w, "failing on purpose", http.StatusServiceUnavailable)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	return s
+}
+
+// Fail makes every request to path, e.g. dto.EXAM_SCHEDULE, fail with 503 Service Unavailable as WIS does when
+// it is overloaded
+func (s *Server) Fail(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failing[path] = true
+}
+
+// Requests returns every request received so far, in order of arrival
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+// Err returns an error describing every request whose form fields did not match what NTU WIS expects
+func (s *Server) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("wistest: %d invalid request(s):\n%s", len(s.errs), strings.Join(s.errs, "\n"))
+}
+
+func (s *Server) handleCourseListInit(w http.ResponseWriter, r *http.Request) {
+	form, ok := s.readForm(w, r, []string{"acadsem", "r_course_yr", "r_subj_code", "boption", "acad", "semester"})
+	if !ok {
+		return
+	}
+
+	s.check(r, acadYearSemPattern.MatchString(form.Get("acadsem")), "acadsem %q is not in the form 2023_1", form.Get("acadsem"))
+
+	s.serveFixture(w, "fixtures/init.html")
+}
+
+func (s *Server) handleContentOfCourses(w http.ResponseWriter, r *http.Request) {
+	form, ok := s.readForm(w, r, []string{"acadsem", "r_course_yr", "r_subj_code", "boption", "acad", "semester"})
+	if !ok {
+		return
+	}
+
+	acadYearSem := form.Get("acadsem")
+	s.check(r, acadYearSemPattern.MatchString(acadYearSem), "acadsem %q is not in the form 2023_1", acadYearSem)
+	s.check(r, acadYearSem == form.Get("acad")+"_"+form.Get("semester"), "acad %q and semester %q do not match acadsem %q", form.Get("acad"), form.Get("semester"), acadYearSem)
+	s.check(r, form.Get("boption") == "CLoad", "boption is %q, expected CLoad", form.Get("boption"))
+
+	s.serveProgramme(w, r, "content", form.Get("r_course_yr"))
+}
+
+func (s *Server) handleClassSchedule(w http.ResponseWriter, r *http.Request) {
+	form, ok := s.readForm(w, r, []string{"acadsem", "r_course_yr", "r_subj_code", "boption", "r_search_type", "staff_access"})
+	if !ok {
+		return
+	}
+
+	s.check(r, scheduleAcadYearSemPattern.MatchString(form.Get("acadsem")), "acadsem %q is not in the form 2023;1", form.Get("acadsem"))
+	s.check(r, form.Get("boption") == "CLoad", "boption is %q, expected CLoad", form.Get("boption"))
+
+	s.serveProgramme(w, r, "schedule", form.Get("r_course_yr"))
+}
+
+func (s *Server) handleExamSchedule(w http.ResponseWriter, r *http.Request) {
+	form, ok := s.readForm(w, r, []string{
+		"p_subj", "p_plan_no", "p_exam_dt", "p_start_time", "p_dept", "p_venue", "p_matric",
+		"academic_session", "p_exam_yr", "p_semester", "p_type", "bOption",
+	})
+	if !ok {
+		return
+	}
+
+	s.check(r, form.Get("p_exam_yr")+"_"+form.Get("p_semester") == SEMESTER, "p_exam_yr %q and p_semester %q do not match %s", form.Get("p_exam_yr"), form.Get("p_semester"), SEMESTER)
+	s.check(r, form.Get("p_plan_no") == "110", "p_plan_no is %q, expected 110", form.Get("p_plan_no"))
+	s.check(r, form.Get("p_type") == "UE", "p_type is %q, expected UE", form.Get("p_type"))
+	s.check(r, form.Get("bOption") == "Next", "bOption is %q, expected Next", form.Get("bOption"))
+
+	name := "fixtures/exam/" + form.Get("p_subj") + ".html"
+	if _, err := fixtures.Open(name); err != nil {
+		name = "fixtures/exam/none.html"
+	}
+	s.serveFixture(w, name)
+}
+
+// readForm records the request and checks that it is a form POST carrying exactly the expected fields
+func (s *Server) readForm(w http.ResponseWriter, r *http.Request, fields []string) (url.Values, bool) {
+	if r.Method != http.MethodPost {
+		s.fail(r, "method is %s, expected POST", r.Method)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil, false
+	}
+
+	if err := r.ParseForm(); err != nil {
+		s.fail(r, "unable to parse form: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Path: r.URL.Path, Form: r.PostForm})
+	s.mu.Unlock()
+
+	expected := make(map[string]bool)
+	for _, field := range fields {
+		expected[field] = true
+		s.check(r, len(r.PostForm[field]) == 1, "field %q sent %d times, expected once", field, len(r.PostForm[field]))
+	}
+
+	for field := range r.PostForm {
+		s.check(r, expected[field], "unexpected field %q", field)
+	}
+
+	return r.PostForm, true
+}
+
+func (s *Server) serveProgramme(w http.ResponseWriter, r *http.Request, kind string, courseYearProg string) {
+	for _, programme := range PROGRAMMES {
+		if programme == courseYearProg {
+			s.serveFixture(w, "fixtures/"+kind+"/"+strings.ReplaceAll(programme, ";", "_")+".html")
+			return
+		}
+	}
+
+	s.fail(r, "r_course_yr %q is not one of %v", courseYearProg, PROGRAMMES)
+	http.NotFound(w, r)
+}
+
+func (s *Server) serveFixture(w http.ResponseWriter, name string) {
+	data, err := fixtures.ReadFile(name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(data)
+}
+
+func (s *Server) check(r *http.Request, ok bool, format string, args ...interface{}) {
+	if !ok {
+		s.fail(r, format, args...)
+	}
+}
+
+func (s *Server) fail(r *http.Request, format string, args ...interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.errs = append(s.errs, r.URL.Path+": "+fmt.Sprintf(format, args...))
+}
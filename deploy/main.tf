@@ -120,8 +120,14 @@ resource "azurerm_logic_app_action_http" "lact" {
   name         = "request-to-scrape"
   logic_app_id = azurerm_logic_app_workflow.lapp.id
 
-  method  = "GET"
-  uri     = "http://${azurerm_container_group.acg.dns_name_label}.southeastasia.azurecontainer.io:8080"
+  method  = "POST"
+  uri     = "http://${azurerm_container_group.acg.dns_name_label}.southeastasia.azurecontainer.io:8080/jobs"
+  headers = {
+    "Content-Type" = "application/json"
+  }
+  body = jsonencode({
+    semester = "latest"
+  })
 }
 
 resource "azurerm_logic_app_trigger_recurrence" "sem1" {